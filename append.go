@@ -0,0 +1,284 @@
+package jerrors
+
+import (
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// maxPooledBuffer is the largest buffer put back in bufferPool, so one huge Error does not pin
+// its memory.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+func getBuffer() *[]byte { return bufferPool.Get().(*[]byte) }
+
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
+
+// output writes b as a line to the standard logger. b is passed without a copy, so it must not
+// change until output returns. calldepth is the number of frames from the caller of output to the
+// call site the logger reports, like log.Output, so each entry point reports its own caller.
+func output(calldepth int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+
+	// Output copies the string into the logger's own buffer, so a string sharing b's memory is
+	// safe and saves a copy of every line.
+	log.Default().Output(calldepth+1, unsafe.String(unsafe.SliceData(b), len(b)))
+}
+
+// AppendJSON appends the JSON of e, as written by MarshalJSON, to b and returns the result.
+// It is written by hand so logging does not need reflection or allocations. Lazy Metadata values
+// are computed.
+func (e Error) AppendJSON(b []byte) []byte {
+	return e.appendJSON(b, true)
+}
+
+// appendJSON appends the JSON of e, leaving out the Level unless withLevel is set.
+func (e Error) appendJSON(b []byte, withLevel bool) []byte {
+	b = append(b, '{')
+	start := len(b)
+	key := func(name string) {
+		if len(b) > start {
+			b = append(b, ',')
+		}
+		b = append(b, '"')
+		b = append(b, name...)
+		b = append(b, '"', ':')
+	}
+
+	c := config.Load()
+	if c.LogSchemaVersion {
+		key("schema_version")
+		b = appendJSONString(b, SchemaVersion)
+	}
+
+	if e.Time != nil {
+		key("time")
+		b = appendTime(b, *e.Time)
+	}
+
+	if withLevel && e.Level != 0 {
+		key("level")
+		b = appendJSONString(b, e.Level.String())
+	}
+
+	if e.Message != "" {
+		key("message")
+		b = appendJSONString(b, e.Message)
+	}
+
+	if len(e.Field) > 0 {
+		key("field")
+		b = appendPointer(b, e.Field)
+	}
+
+	if p := e.Position; p != nil {
+		key("position")
+		b = append(b, '{')
+		if p.File != "" {
+			b = append(b, `"file":`...)
+			b = appendJSONString(b, p.File)
+			b = append(b, ',')
+		}
+		b = append(b, `"line":`...)
+		b = strconv.AppendInt(b, int64(p.Line), 10)
+		for _, f := range [...]struct {
+			name  string
+			value int
+		}{{"column", p.Column}, {"end_line", p.EndLine}, {"end_column", p.EndColumn}} {
+			if f.value != 0 {
+				b = append(b, `,"`...)
+				b = append(b, f.name...)
+				b = append(b, `":`...)
+				b = strconv.AppendInt(b, int64(f.value), 10)
+			}
+		}
+		b = append(b, '}')
+	}
+
+	if e.Retryable {
+		key("retryable")
+		b = append(b, "true"...)
+	}
+
+	if e.Temporary {
+		key("temporary")
+		b = append(b, "true"...)
+	}
+
+	if len(e.Metadata) > 0 || len(e.lazy) > 0 {
+		key("metadata")
+		b = appendMetadata(b, e.Metadata, e.keys, e.lazy)
+	}
+
+	if e.RetryAfter > 0 {
+		key("retry_after")
+		b = appendJSONString(b, e.RetryAfter.String())
+	}
+
+	if c.LogFingerprint {
+		key("fingerprint")
+		b = appendJSONString(b, e.Fingerprint())
+	}
+
+	return append(b, '}')
+}
+
+// appendMetadata appends m and the computed lazy values as a JSON object, with the keys in order
+// first and any other keys of m after them, sorted. The values are merged while writing, without
+// copying m, so the Error's Time can stay on the stack when logging.
+func appendMetadata(b []byte, m map[string]string, order []string, lazy []lazyMetadata) []byte {
+	b = append(b, '{')
+	start := len(b)
+	pair := func(k, v string) {
+		if len(b) > start {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, k)
+		b = append(b, ':')
+		b = appendJSONString(b, v)
+	}
+
+	for _, k := range order {
+		if v, ok := m[k]; ok {
+			pair(k, v)
+		} else if i := slices.IndexFunc(lazy, func(l lazyMetadata) bool { return l.key == k }); i >= 0 {
+			pair(k, argString(lazy[i].value()))
+		}
+	}
+
+	// Keys written to the map directly.
+	rest := make([]string, 0, 16)
+	for k := range m {
+		if !slices.Contains(order, k) {
+			rest = append(rest, k)
+		}
+	}
+	slices.Sort(rest)
+
+	for _, k := range rest {
+		pair(k, m[k])
+	}
+
+	return append(b, '}')
+}
+
+// appendTime appends t as JSON using Config.TimeFormat and Config.UTC.
+func appendTime(b []byte, t time.Time) []byte {
+	c := config.Load()
+	if c.UTC {
+		t = t.UTC()
+	}
+
+	switch c.TimeFormat {
+	case "", TimeRFC3339Nano:
+		b = append(b, '"')
+		return append(t.AppendFormat(b, time.RFC3339Nano), '"')
+	case TimeRFC3339:
+		b = append(b, '"')
+		return append(t.AppendFormat(b, time.RFC3339), '"')
+	case TimeUnix:
+		return strconv.AppendInt(b, t.Unix(), 10)
+	case TimeUnixMilli:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	case TimeUnixNano:
+		return strconv.AppendInt(b, t.UnixNano(), 10)
+	default:
+		return appendJSONString(b, t.Format(c.TimeFormat))
+	}
+}
+
+// appendPointer appends p as a JSON string holding its JSON Pointer.
+func appendPointer(b []byte, p FieldPath) []byte {
+	b = append(b, '"')
+	for _, s := range p {
+		b = append(b, '/')
+		if s.IsIndex {
+			b = strconv.AppendInt(b, int64(s.Index), 10)
+			continue
+		}
+		b = appendJSONStringContent(b, pointerEscaper.Replace(s.Name))
+	}
+
+	return append(b, '"')
+}
+
+// appendJSONString appends s as a JSON string, escaped the same way as encoding/json: HTML
+// characters, U+2028 and U+2029 are escaped and invalid UTF-8 is replaced with U+FFFD.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	b = appendJSONStringContent(b, s)
+	return append(b, '"')
+}
+
+const hexDigits = "0123456789abcdef"
+
+func appendJSONStringContent(b []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, s[start:i]...)
+			b = append(b, string(utf8.RuneError)...)
+		case r == '\u2028' || r == '\u2029':
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+
+	return append(b, s[start:]...)
+}
@@ -0,0 +1,259 @@
+package jerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"plain",
+		`quote " and \ slash`,
+		"<html> & stuff",
+		"tab\tnew\nline\rreturn\b\f\x00\x1f",
+		"unicode é ✓ 😀",
+		"line para ",
+		"bad \xff utf8 \xc3",
+	} {
+		want, err := json.Marshal(s)
+		require.NoError(t, err)
+		require.Equal(t, string(want), string(appendJSONString(nil, s)), "string %q", s)
+	}
+}
+
+func TestAppendJSON(t *testing.T) {
+	SetConfig(DefaultConfig())
+	defer SetConfig(DefaultConfig())
+
+	now := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	e := Error{
+		Time:       &now,
+		Level:      WARN,
+		Message:    "bad <input>",
+		Field:      ParseFieldPath("users[1].name"),
+		Position:   &Position{File: "a.go", Line: 3, Column: 4},
+		Retryable:  true,
+		Temporary:  true,
+		RetryAfter: 1500 * time.Millisecond,
+		Metadata:   map[string]string{"user": "test1", "code": "E1", "a": "\"x\""},
+	}
+
+	want := `{"time":"2024-05-06T07:08:09.00000001Z","level":"warn","message":"bad \u003cinput\u003e",` +
+		`"field":"/users/1/name","position":{"file":"a.go","line":3,"column":4},"retryable":true,` +
+		`"temporary":true,"metadata":{"a":"\"x\"","code":"E1","user":"test1"},"retry_after":"1.5s"}`
+	require.Equal(t, want, string(e.AppendJSON(nil)))
+
+	j, err := json.Marshal(e)
+	require.NoError(t, err)
+	require.Equal(t, want, string(j))
+
+	var got Error
+	require.NoError(t, json.Unmarshal(j, &got))
+	require.True(t, e.Equal(got))
+
+	require.Equal(t, `{}`, string(Error{}.AppendJSON(nil)))
+	require.Equal(t, `x{"message":"m"}`, string(Error{Message: "m"}.AppendJSON([]byte("x"))))
+}
+
+func TestAppendJSONConfig(t *testing.T) {
+	c := DefaultConfig()
+	c.TimeFormat = TimeUnixMilli
+	c.LogFingerprint = true
+	c.LogSchemaVersion = true
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	now := time.UnixMilli(1700000000123)
+	e := Error{Time: &now, Level: ERROR, Message: testMessage}
+
+	want := `{"schema_version":"` + SchemaVersion + `","time":1700000000123,"level":"error",` +
+		`"message":"test error","fingerprint":"` + e.Fingerprint() + `"}`
+	require.Equal(t, want, string(e.AppendJSON(nil)))
+}
+
+// logLine returns what f writes to the standard logger.
+func logLine(t *testing.T, f func()) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	SetLogOutput(&buf)
+	defer SetLogOutput(os.Stderr)
+
+	flags := log.Flags()
+	log.SetFlags(0)
+	defer log.SetFlags(flags)
+
+	f()
+	return buf.String()
+}
+
+func TestLog(t *testing.T) {
+	c := DefaultConfig()
+	c.LoggingLevel = DEBUG
+	SetConfig(c)
+	SetClock(NewManualClock(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)))
+	defer func() {
+		SetConfig(DefaultConfig())
+		SetClock(nil)
+	}()
+
+	for _, args := range [][]interface{}{
+		nil,
+		{"user", "test1"},
+		{"user", "test1", "odd"},
+		{"b", 2, "a", 1.5, "c", true, "b", "again", "d", uint8(4)},
+		{"s", []string{"x"}, 7, "<key>", "e", struct{ A int }{1}},
+		{"1", 1, "2", 2, "3", 3, "4", 4, "5", 5, "6", 6, "7", 7, "8", 8, "9", 9},
+	} {
+		want := logLine(t, func() {
+			e := NewError(INFO, testMessage, args...)
+			e.Log()
+		})
+		got := logLine(t, func() { Log(INFO, testMessage, args...) })
+		require.Equal(t, want, got, "args %v", args)
+	}
+
+	c.LoggingLevel = WARN
+	SetConfig(c)
+	require.Empty(t, logLine(t, func() { Log(INFO, testMessage, "user", "test1") }))
+}
+
+func TestLogCallSite(t *testing.T) {
+	c := DefaultConfig()
+	c.LoggingLevel = DEBUG
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	r := new(ExitRecorder)
+	SetExitFunc(r.Exit)
+	defer SetExitFunc(nil)
+
+	callSite := func(f func()) string {
+		var buf bytes.Buffer
+		SetLogOutput(&buf)
+		defer SetLogOutput(os.Stderr)
+
+		flags := log.Flags()
+		log.SetFlags(log.Lshortfile)
+		defer log.SetFlags(flags)
+
+		f()
+		return buf.String()
+	}
+
+	errs := New()
+	errs.NewError(ERROR, testMessage)
+	e := NewError(ERROR, testMessage)
+
+	for name, f := range map[string]func(){
+		"Log":          func() { Log(INFO, testMessage) },
+		"Log fallback": func() { Log(INFO, testMessage, "1", 1, "2", 2, "3", 3, "4", 4, "5", 5, "6", 6, "7", 7, "8", 8, "9", 9) },
+		"Logf":         func() { Logf(INFO, "%s", testMessage) },
+		"Debugf":       func() { Debugf("%s", testMessage) },
+		"Error.Log":    func() { e.Log() },
+		"Error.Fatal":  func() { e.Fatal() },
+		"Errors.Log":   func() { errs.Log() },
+		"Errors.Fatal": func() { errs.Fatal("") },
+	} {
+		got := callSite(f)
+		require.True(t, strings.HasPrefix(got, "append_test.go:"), "%s: %s", name, got)
+	}
+}
+
+func TestLogNoLevel(t *testing.T) {
+	c := DefaultConfig()
+	c.LogLevel = false
+	c.LogTime = false
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	require.Equal(t, `{"message":"test error","metadata":{"user":"test1"}}`+"\n",
+		logLine(t, func() { Log(ERROR, testMessage, mdUserKey, mdUserVal) }))
+}
+
+func TestLogAllocs(t *testing.T) {
+	SetConfig(DefaultConfig())
+	SetLogOutput(io.Discard)
+	defer SetLogOutput(os.Stderr)
+
+	// Constant args, as converting other values to interfaces allocates in the caller.
+	allocs := testing.AllocsPerRun(100, func() {
+		Log(DEBUG, "test error", "type", "test", "user", "test1")
+	})
+	require.Zero(t, allocs, "filtered Log")
+
+	allocs = testing.AllocsPerRun(100, func() {
+		Log(ERROR, "test error", "type", "test", "user", "test1", "count", 3)
+	})
+	require.Zero(t, allocs, "Log")
+
+	e := NewError(ERROR, testMessage, mdTypeKey, mdTypeVal, mdUserKey, mdUserVal)
+	buf := make([]byte, 0, 1024)
+	allocs = testing.AllocsPerRun(100, func() {
+		buf = e.AppendJSON(buf[:0])
+	})
+	require.LessOrEqual(t, allocs, 1.0, "AppendJSON")
+	require.True(t, strings.HasPrefix(string(buf), "{"))
+}
+
+func BenchmarkLogFiltered(b *testing.B) {
+	SetConfig(DefaultConfig())
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		Log(DEBUG, "test error", "type", "test", "user", "test1")
+	}
+}
+
+func BenchmarkLog(b *testing.B) {
+	SetConfig(DefaultConfig())
+	SetLogOutput(io.Discard)
+	defer SetLogOutput(os.Stderr)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		Log(ERROR, "test error", "type", "test", "user", "test1")
+	}
+}
+
+func BenchmarkNewErrorLog(b *testing.B) {
+	SetConfig(DefaultConfig())
+	SetLogOutput(io.Discard)
+	defer SetLogOutput(os.Stderr)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		e := NewError(ERROR, testMessage, mdTypeKey, mdTypeVal, mdUserKey, mdUserVal)
+		e.Log()
+	}
+}
+
+func BenchmarkAppendJSON(b *testing.B) {
+	SetConfig(DefaultConfig())
+	e := NewError(ERROR, testMessage, mdTypeKey, mdTypeVal, mdUserKey, mdUserVal)
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		buf = e.AppendJSON(buf[:0])
+	}
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	SetConfig(DefaultConfig())
+	e := NewError(ERROR, testMessage, mdTypeKey, mdTypeVal, mdUserKey, mdUserVal)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(e)
+	}
+}
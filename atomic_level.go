@@ -0,0 +1,76 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// loggingLevel is the minimum Level logged by Log. It is kept in sync with Config.LoggingLevel
+// by SetConfig and can be changed safely while logging.
+var loggingLevel = NewAtomicLevel(INFO)
+
+// AtomicLevel is a Level that can be read and changed concurrently.
+type AtomicLevel struct {
+	v atomic.Int32
+}
+
+// NewAtomicLevel creates a new AtomicLevel set to level.
+func NewAtomicLevel(level Level) *AtomicLevel {
+	a := &AtomicLevel{}
+	a.SetLevel(level)
+	return a
+}
+
+// LoggingLevel returns the AtomicLevel holding the minimum Level to log.
+func LoggingLevel() *AtomicLevel { return loggingLevel }
+
+// SetLogLevel changes the minimum Level to log. Safe to call while logging.
+func SetLogLevel(level Level) { loggingLevel.SetLevel(level) }
+
+// Level returns the current Level.
+func (a *AtomicLevel) Level() Level { return Level(a.v.Load()) }
+
+// SetLevel changes the current Level.
+func (a *AtomicLevel) SetLevel(level Level) { a.v.Store(int32(level)) }
+
+// Swap changes the current Level and returns the previous one.
+func (a *AtomicLevel) Swap(level Level) Level { return Level(a.v.Swap(int32(level))) }
+
+// Enabled returns true if level would be logged.
+func (a *AtomicLevel) Enabled(level Level) bool { return level >= a.Level() }
+
+// levelPayload is the JSON body used by AtomicLevel.ServeHTTP.
+type levelPayload struct {
+	Level Level `json:"level"`
+}
+
+// ServeHTTP lets the Level be read with GET and changed with PUT using a JSON body such as
+// {"level":"debug"}. Both respond with the current Level in the same format.
+// Example:
+// http.Handle("/debug/level", jerrors.LoggingLevel())
+func (a *AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var p levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil || p.Level < DEBUG || p.Level > FATAL {
+			w.WriteHeader(http.StatusBadRequest)
+			e := NewError(ERROR, "invalid level", "allowed", "debug, info, warn, error, fatal")
+			_, _ = w.Write([]byte(e.String()))
+			return
+		}
+
+		a.SetLevel(p.Level)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		e := NewError(ERROR, "method not allowed", "method", r.Method)
+		_, _ = w.Write([]byte(e.String()))
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(levelPayload{Level: a.Level()})
+}
@@ -0,0 +1,100 @@
+package jerrors
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicLevel(t *testing.T) {
+	a := NewAtomicLevel(WARN)
+	require.Equal(t, WARN, a.Level())
+	require.False(t, a.Enabled(INFO))
+	require.True(t, a.Enabled(WARN))
+
+	require.Equal(t, WARN, a.Swap(DEBUG))
+	require.Equal(t, DEBUG, a.Level())
+	require.True(t, a.Enabled(DEBUG))
+}
+
+func TestAtomicLevelConfig(t *testing.T) {
+	SetConfig(DefaultConfig())
+	require.Equal(t, INFO, LoggingLevel().Level())
+
+	c := DefaultConfig()
+	c.LoggingLevel = ERROR
+	SetConfig(c)
+	require.Equal(t, ERROR, LoggingLevel().Level())
+
+	SetLogLevel(DEBUG)
+	require.Equal(t, DEBUG, GetConfig().LoggingLevel)
+	SetConfig(DefaultConfig())
+}
+
+func TestAtomicLevelLog(t *testing.T) {
+	SetConfig(DefaultConfig())
+	defer SetConfig(DefaultConfig())
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	err := debugErr
+	err.Log()
+	require.Empty(t, buf.String())
+
+	SetLogLevel(DEBUG)
+	err.Log()
+	require.Contains(t, buf.String(), "debug")
+
+	// Changing the level while logging is safe.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetLogLevel(ERROR)
+		}()
+		go func() {
+			defer wg.Done()
+			errs := New()
+			errs.Add(debugErr)
+			_ = errs.Error()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAtomicLevelServeHTTP(t *testing.T) {
+	a := NewAtomicLevel(INFO)
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/level", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"level":"info"}`, strings.TrimSpace(rec.Body.String()))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	rec = httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"level":"debug"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"level":"debug"}`, strings.TrimSpace(rec.Body.String()))
+	require.Equal(t, DEBUG, a.Level())
+
+	rec = httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"level":"loud"}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), `"message":"invalid level"`)
+	require.Equal(t, DEBUG, a.Level())
+
+	rec = httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/level", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	require.Equal(t, "GET, PUT", rec.Header().Get("Allow"))
+}
@@ -0,0 +1,456 @@
+package jerrors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The binary encodings write an Error as a map with these integer keys, leaving out empty values
+// like the JSON does. Position is a map with the keys 0 file, 1 line, 2 column, 3 end_line and
+// 4 end_column. An Errors is a map with the keys 0 name, 1 errors, 2 level and 3 groups. Unknown
+// keys are skipped when decoding.
+const (
+	binaryTime = iota
+	binaryLevel
+	binaryMessage
+	binaryField
+	binaryPosition
+	binaryRetryable
+	binaryTemporary
+	binaryRetryAfter
+	binaryMetadata
+)
+
+const (
+	binaryName = iota
+	binaryErrors
+	binaryErrorsLevel
+	binaryGroups
+)
+
+// binaryEncoder writes the items of a binary encoding. See cborEncoder and msgpackEncoder.
+type binaryEncoder interface {
+	writeMap(n int)
+	writeArray(n int)
+	writeUint(v uint64)
+	writeInt(v int64)
+	writeString(s string)
+	writeBool(v bool)
+	writeTime(t time.Time)
+}
+
+// binaryDecoder reads the items of a binary encoding. See cborDecoder and msgpackDecoder.
+type binaryDecoder interface {
+	readMap() (int, error)
+	readArray() (int, error)
+	readUint() (uint64, error)
+	readInt() (int64, error)
+	readString() (string, error)
+	readBool() (bool, error)
+	readTime() (time.Time, error)
+	// skip reads past the next item, whatever its type. It returns errBinaryDepth if the item
+	// nests deeper than maxBinaryDepth.
+	skip() error
+	// rest returns the number of bytes left.
+	rest() int
+}
+
+// errBinaryTrailing is returned when bytes are left after the encoded Error or Errors.
+var errBinaryTrailing = errors.New("jerrors: unexpected data after value")
+
+// maxBinaryDepth limits how deeply groups and skipped values may be nested, so hostile input
+// cannot overflow the stack.
+const maxBinaryDepth = 100
+
+// errBinaryDepth is returned when the input is nested deeper than maxBinaryDepth.
+var errBinaryDepth = errors.New("jerrors: value nested too deeply")
+
+func encodeError(w binaryEncoder, e Error) {
+	e = e.Resolve()
+	n := 0
+	for _, set := range [...]bool{
+		e.Time != nil, e.Level != 0, e.Message != "", len(e.Field) > 0, e.Position != nil,
+		e.Retryable, e.Temporary, e.RetryAfter != 0, len(e.Metadata) > 0,
+	} {
+		if set {
+			n++
+		}
+	}
+	w.writeMap(n)
+
+	if e.Time != nil {
+		w.writeUint(binaryTime)
+		w.writeTime(*e.Time)
+	}
+
+	if e.Level != 0 {
+		w.writeUint(binaryLevel)
+		w.writeUint(uint64(e.Level))
+	}
+
+	if e.Message != "" {
+		w.writeUint(binaryMessage)
+		w.writeString(e.Message)
+	}
+
+	if len(e.Field) > 0 {
+		w.writeUint(binaryField)
+		w.writeString(e.Field.Pointer())
+	}
+
+	if e.Position != nil {
+		w.writeUint(binaryPosition)
+		encodePosition(w, *e.Position)
+	}
+
+	if e.Retryable {
+		w.writeUint(binaryRetryable)
+		w.writeBool(true)
+	}
+
+	if e.Temporary {
+		w.writeUint(binaryTemporary)
+		w.writeBool(true)
+	}
+
+	if e.RetryAfter != 0 {
+		w.writeUint(binaryRetryAfter)
+		w.writeInt(int64(e.RetryAfter))
+	}
+
+	if len(e.Metadata) > 0 {
+		w.writeUint(binaryMetadata)
+		keys := e.MetadataKeys()
+		w.writeMap(len(keys))
+		for _, k := range keys {
+			w.writeString(k)
+			w.writeString(e.Metadata[k])
+		}
+	}
+}
+
+func encodePosition(w binaryEncoder, p Position) {
+	values := [...]int{p.Line, p.Column, p.EndLine, p.EndColumn}
+	n := 1
+	for _, v := range values {
+		if v != 0 {
+			n++
+		}
+	}
+	w.writeMap(n)
+
+	w.writeUint(0)
+	w.writeString(p.File)
+	for i, v := range values {
+		if v != 0 {
+			w.writeUint(uint64(i + 1))
+			w.writeInt(int64(v))
+		}
+	}
+}
+
+func encodeErrors(w binaryEncoder, e *Errors) {
+	n := 2
+	if e.Name != "" {
+		n++
+	}
+	if len(e.Groups) > 0 {
+		n++
+	}
+	w.writeMap(n)
+
+	if e.Name != "" {
+		w.writeUint(binaryName)
+		w.writeString(e.Name)
+	}
+
+	w.writeUint(binaryErrors)
+	w.writeArray(len(e.Errors))
+	for _, err := range e.Errors {
+		encodeError(w, err)
+	}
+
+	w.writeUint(binaryErrorsLevel)
+	w.writeUint(uint64(e.Level))
+
+	if len(e.Groups) > 0 {
+		w.writeUint(binaryGroups)
+		w.writeArray(len(e.Groups))
+		for _, g := range e.Groups {
+			encodeErrors(w, g)
+		}
+	}
+}
+
+// readLen reads a map or array length and checks that the input can hold that many items.
+func readLen(r binaryDecoder, read func() (int, error)) (int, error) {
+	n, err := read()
+	if err != nil {
+		return 0, err
+	}
+
+	if n > r.rest() {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return n, nil
+}
+
+func readLevel(r binaryDecoder) (Level, error) {
+	l, err := r.readUint()
+	if err != nil {
+		return 0, err
+	}
+
+	if l > uint64(FATAL) {
+		return 0, fmt.Errorf("jerrors: invalid level %d", l)
+	}
+
+	return Level(l), nil
+}
+
+func decodeError(r binaryDecoder, e *Error) error {
+	n, err := readLen(r, r.readMap)
+	if err != nil {
+		return err
+	}
+
+	*e = Error{}
+	for i := 0; i < n; i++ {
+		key, err := r.readUint()
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case binaryTime:
+			var t time.Time
+			if t, err = r.readTime(); err == nil {
+				e.Time = &t
+			}
+		case binaryLevel:
+			e.Level, err = readLevel(r)
+		case binaryMessage:
+			e.Message, err = r.readString()
+		case binaryField:
+			var s string
+			if s, err = r.readString(); err == nil {
+				e.Field = ParsePointer(s)
+			}
+		case binaryPosition:
+			e.Position = &Position{}
+			err = decodePosition(r, e.Position)
+		case binaryRetryable:
+			e.Retryable, err = r.readBool()
+		case binaryTemporary:
+			e.Temporary, err = r.readBool()
+		case binaryRetryAfter:
+			var d int64
+			d, err = r.readInt()
+			e.RetryAfter = time.Duration(d)
+		case binaryMetadata:
+			err = decodeMetadata(r, e)
+		default:
+			err = r.skip()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeMetadata(r binaryDecoder, e *Error) error {
+	n, err := readLen(r, r.readMap)
+	if err != nil {
+		return err
+	}
+
+	e.Metadata = make(map[string]string, n)
+	e.keys = make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		k, err := r.readString()
+		if err != nil {
+			return err
+		}
+
+		v, err := r.readString()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := e.Metadata[k]; !ok {
+			e.keys = append(e.keys, k)
+		}
+		e.Metadata[k] = v
+	}
+
+	return nil
+}
+
+func decodePosition(r binaryDecoder, p *Position) error {
+	n, err := readLen(r, r.readMap)
+	if err != nil {
+		return err
+	}
+
+	values := [...]*int{&p.Line, &p.Column, &p.EndLine, &p.EndColumn}
+	for i := 0; i < n; i++ {
+		key, err := r.readUint()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case key == 0:
+			p.File, err = r.readString()
+		case key <= uint64(len(values)):
+			var v int64
+			v, err = r.readInt()
+			*values[key-1] = int(v)
+		default:
+			err = r.skip()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeErrors decodes an Errors at the given group depth, the top level Errors being 0.
+func decodeErrors(r binaryDecoder, e *Errors, depth int) error {
+	if depth > maxBinaryDepth {
+		return errBinaryDepth
+	}
+
+	n, err := readLen(r, r.readMap)
+	if err != nil {
+		return err
+	}
+
+	parent := e.parent
+	*e = New()
+	e.parent = parent
+	for i := 0; i < n; i++ {
+		key, err := r.readUint()
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case binaryName:
+			e.Name, err = r.readString()
+		case binaryErrors:
+			err = decodeErrorList(r, e)
+		case binaryErrorsLevel:
+			e.Level, err = readLevel(r)
+		case binaryGroups:
+			err = decodeGroups(r, e, depth)
+		default:
+			err = r.skip()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeErrorList(r binaryDecoder, e *Errors) error {
+	n, err := readLen(r, r.readArray)
+	if err != nil {
+		return err
+	}
+
+	e.Errors = make([]Error, n)
+	for i := range e.Errors {
+		if err := decodeError(r, &e.Errors[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeGroups(r binaryDecoder, e *Errors, depth int) error {
+	n, err := readLen(r, r.readArray)
+	if err != nil {
+		return err
+	}
+
+	e.Groups = make([]*Errors, n)
+	for i := range e.Groups {
+		g := &Errors{parent: e}
+		if err := decodeErrors(r, g, depth+1); err != nil {
+			return err
+		}
+		e.Groups[i] = g
+	}
+
+	return nil
+}
+
+// unmarshalBinary runs decode and checks that it read all of the input.
+func unmarshalBinary(r binaryDecoder, decode func(binaryDecoder) error) error {
+	if err := decode(r); err != nil {
+		return err
+	}
+
+	if r.rest() > 0 {
+		return errBinaryTrailing
+	}
+
+	return nil
+}
+
+// binaryReader holds the unread input of a binaryDecoder.
+type binaryReader struct {
+	b []byte
+}
+
+func (r *binaryReader) rest() int { return len(r.b) }
+
+func (r *binaryReader) next() (byte, error) {
+	if len(r.b) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c, nil
+}
+
+// take returns the next n bytes.
+func (r *binaryReader) take(n uint64) ([]byte, error) {
+	if n > uint64(len(r.b)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b, nil
+}
+
+// uint reads an n byte big endian unsigned integer.
+func (r *binaryReader) uint(n int) (uint64, error) {
+	b, err := r.take(uint64(n))
+	if err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	return v, nil
+}
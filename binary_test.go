@@ -0,0 +1,316 @@
+package jerrors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// binaryCodec is one of the binary encodings under test.
+type binaryCodec struct {
+	name            string
+	marshalError    func(Error) ([]byte, error)
+	unmarshalError  func(*Error, []byte) error
+	marshalErrors   func(*Errors) ([]byte, error)
+	unmarshalErrors func(*Errors, []byte) error
+}
+
+var binaryCodecs = []binaryCodec{
+	{"cbor", Error.MarshalCBOR, (*Error).UnmarshalCBOR, (*Errors).MarshalCBOR, (*Errors).UnmarshalCBOR},
+	{"msgpack", Error.MarshalMsgpack, (*Error).UnmarshalMsgpack, (*Errors).MarshalMsgpack, (*Errors).UnmarshalMsgpack},
+}
+
+func testBinaryError() Error {
+	ts := time.Date(2024, 7, 18, 13, 9, 25, 355123456, time.UTC)
+	return Error{
+		Time:       &ts,
+		Level:      ERROR,
+		Message:    "open app.conf: no such file or directory",
+		Field:      FieldPath{{Name: "servers"}, {Index: 2, IsIndex: true}, {Name: "a/b"}},
+		Position:   &Position{File: "app.conf", Line: 3, Column: 7, EndLine: 3, EndColumn: 300},
+		Retryable:  true,
+		Temporary:  true,
+		RetryAfter: 1500 * time.Millisecond,
+		Metadata:   map[string]string{CauseKey: "no such file or directory", CodeKey: "path_error", "long": string(make([]byte, 70000))},
+	}
+}
+
+func requireSameError(t *testing.T, want, got Error) {
+	t.Helper()
+	require.True(t, want.Equal(got), "want %v\ngot  %v", want, got)
+	if want.Time == nil {
+		require.Nil(t, got.Time)
+		return
+	}
+
+	require.NotNil(t, got.Time)
+	require.True(t, want.Time.Equal(*got.Time), "want %v, got %v", want.Time, got.Time)
+}
+
+func TestBinaryErrorRoundTrip(t *testing.T) {
+	times := []time.Time{
+		time.Date(2024, 7, 18, 13, 9, 25, 0, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 999, time.UTC),
+		time.Date(2600, 1, 1, 0, 0, 0, 1, time.UTC),
+	}
+
+	for _, c := range binaryCodecs {
+		t.Run(c.name, func(t *testing.T) {
+			errs := []Error{{}, testBinaryError(), {Level: DEBUG, Position: &Position{}, RetryAfter: -time.Second}}
+			for _, ts := range times {
+				e := testBinaryError()
+				e.Time = &ts
+				errs = append(errs, e)
+			}
+
+			for _, want := range errs {
+				b, err := c.marshalError(want)
+				require.Nil(t, err)
+
+				var got Error
+				require.Nil(t, c.unmarshalError(&got, b))
+				requireSameError(t, want, got)
+			}
+		})
+	}
+}
+
+func TestBinaryErrorsRoundTrip(t *testing.T) {
+	for _, c := range binaryCodecs {
+		t.Run(c.name, func(t *testing.T) {
+			want := New()
+			want.Name = "config"
+			want.Add(testBinaryError())
+			want.Group("db").NewError(WARN, "slow query", "table", "users")
+			want.Group("db").Group("replica").NewError(FATAL, "unreachable")
+			want.Group("empty")
+
+			b, err := c.marshalErrors(&want)
+			require.Nil(t, err)
+
+			var got Errors
+			require.Nil(t, c.unmarshalErrors(&got, b))
+			require.True(t, want.Equal(got))
+			require.Equal(t, FATAL, got.Level)
+			require.Equal(t, "config", got.Name)
+			requireSameError(t, want.Errors[0], got.Errors[0])
+			require.Equal(t, "db.replica", got.Group("db").Group("replica").Path())
+
+			empty := New()
+			b, err = c.marshalErrors(&empty)
+			require.Nil(t, err)
+			require.Nil(t, c.unmarshalErrors(&got, b))
+			require.True(t, got.IsEmpty())
+			require.NotNil(t, got.Errors)
+		})
+	}
+}
+
+func TestBinaryEncoding(t *testing.T) {
+	e := Error{Level: ERROR, Message: "a", Metadata: map[string]string{"k": "v"}}
+
+	b, err := e.MarshalCBOR()
+	require.Nil(t, err)
+	require.Equal(t, "a3"+"0104"+"026161"+"08a1616b6176", hex.EncodeToString(b))
+
+	b, err = e.MarshalMsgpack()
+	require.Nil(t, err)
+	require.Equal(t, "83"+"0104"+"02a161"+"0881a16ba176", hex.EncodeToString(b))
+
+	ts := time.Unix(1721322565, 0)
+	e = Error{Time: &ts}
+	b, err = e.MarshalCBOR()
+	require.Nil(t, err)
+	require.Equal(t, "a1"+"00"+"c11a66994c45", hex.EncodeToString(b))
+
+	b, err = e.MarshalMsgpack()
+	require.Nil(t, err)
+	require.Equal(t, "81"+"00"+"d6ff66994c45", hex.EncodeToString(b))
+}
+
+func TestBinaryDecodeForeign(t *testing.T) {
+	tests := []struct {
+		name   string
+		codec  string
+		hex    string
+		want   time.Time
+		unmark func(*Error, []byte) error
+	}{
+		// {0: 0("2024-07-18T13:09:25.5Z"), 99: [1, "x", {1: 2.5}]}
+		{"cbor rfc3339", "cbor", "a2" + "00c0" + "76" + hex.EncodeToString([]byte("2024-07-18T13:09:25.5Z")) + "1863" + "8301" + "6178" + "a101f94100",
+			time.Date(2024, 7, 18, 13, 9, 25, 5e8, time.UTC), (*Error).UnmarshalCBOR},
+		// {0: 1(1721322565.5)}
+		{"cbor float", "cbor", "a1" + "00c1" + "fb41d9a65311600000", time.Unix(1721322565, 5e8), (*Error).UnmarshalCBOR},
+		// {0: 1001({1: 1721322565, -3: 250})}
+		{"cbor extended", "cbor", "a1" + "00" + "d903e9" + "a2" + "011a66994c45" + "2218fa", time.Unix(1721322565, 25e7), (*Error).UnmarshalCBOR},
+		// {0: timestamp 96, 99: bin8 [1 2], 98: ext8 len 1 type 5, 97: float64}
+		{"msgpack", "msgpack", "84" + "00c70cff" + "00000001" + "0000000066994c45" + "63c4020102" + "62c7010507" + "61cb0000000000000000",
+			time.Unix(1721322565, 1), (*Error).UnmarshalMsgpack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.hex)
+			require.Nil(t, err)
+
+			var e Error
+			require.Nil(t, tt.unmark(&e, b))
+			require.NotNil(t, e.Time)
+			require.True(t, tt.want.Equal(*e.Time), "want %v, got %v", tt.want, e.Time)
+		})
+	}
+}
+
+func TestBinaryDecodeInvalid(t *testing.T) {
+	for _, c := range binaryCodecs {
+		t.Run(c.name, func(t *testing.T) {
+			errs := New()
+			errs.Add(testBinaryError())
+			errs.Group("db").NewError(WARN, "slow query")
+			b, err := c.marshalErrors(&errs)
+			require.Nil(t, err)
+
+			var got Errors
+			for i := 0; i < len(b); i += 97 {
+				require.NotNil(t, c.unmarshalErrors(&got, b[:i]), "prefix %d", i)
+			}
+			require.NotNil(t, c.unmarshalErrors(&got, b[:len(b)-1]))
+			require.ErrorIs(t, c.unmarshalErrors(&got, append(b, 0)), errBinaryTrailing)
+
+			// An Error is not an Errors.
+			e, err := c.marshalError(Error{Level: 9})
+			require.Nil(t, err)
+			require.NotNil(t, c.unmarshalErrors(&got, e))
+
+			var ge Error
+			require.NotNil(t, c.unmarshalError(&ge, e), "level 9")
+			require.NotNil(t, c.unmarshalError(&ge, []byte{0xa1}))
+		})
+	}
+}
+
+func TestBinaryDecodeDeep(t *testing.T) {
+	// An Error with one unknown key holding a million nested one-entry maps.
+	nested := map[string][]byte{
+		"cbor":    {0xa1, 0x18, 0x63},
+		"msgpack": {0x81, 0x63},
+	}
+	entry := map[string][]byte{"cbor": {0xa1, 0x00}, "msgpack": {0x81, 0x00}}
+
+	for _, c := range binaryCodecs {
+		t.Run(c.name, func(t *testing.T) {
+			b := append(bytes.Repeat(entry[c.name], 1_000_000), 0x00)
+			b = append(append([]byte{}, nested[c.name]...), b...)
+
+			var e Error
+			require.ErrorIs(t, c.unmarshalError(&e, b), errBinaryDepth)
+
+			// Groups are limited too, while ordinary nesting still decodes.
+			for _, depth := range []int{maxBinaryDepth, maxBinaryDepth + 1} {
+				errs := New()
+				g := &errs
+				for i := 0; i < depth; i++ {
+					g = g.Group("g")
+				}
+				g.NewError(ERROR, testMessage)
+
+				b, err := c.marshalErrors(&errs)
+				require.Nil(t, err)
+
+				var got Errors
+				err = c.unmarshalErrors(&got, b)
+				if depth > maxBinaryDepth {
+					require.ErrorIs(t, err, errBinaryDepth)
+				} else {
+					require.Nil(t, err)
+					require.True(t, errs.Equal(got))
+				}
+			}
+		})
+	}
+}
+
+func TestBinarySmallerThanJSON(t *testing.T) {
+	errs := benchmarkErrors()
+	j, err := json.Marshal(&errs)
+	require.Nil(t, err)
+
+	for _, c := range binaryCodecs {
+		b, err := c.marshalErrors(&errs)
+		require.Nil(t, err)
+		require.Less(t, len(b), len(j)*3/4, c.name)
+	}
+}
+
+func benchmarkErrors() Errors {
+	ts := time.Date(2024, 7, 18, 13, 9, 25, 355123456, time.UTC)
+	errs := New()
+	for i := 0; i < 20; i++ {
+		errs.Add(Error{
+			Time:     &ts,
+			Level:    ERROR,
+			Message:  "query users failed",
+			Field:    FieldPath{{Name: "user"}, {Name: "id"}},
+			Metadata: map[string]string{CodeKey: "timeout", CauseKey: "context deadline exceeded", "host": "db1", "table": "users"},
+		})
+	}
+	errs.Group("cache").NewError(WARN, "miss", "key", "user:1")
+
+	return errs
+}
+
+func BenchmarkEncodeErrors(b *testing.B) {
+	errs := benchmarkErrors()
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		var n int
+		for i := 0; i < b.N; i++ {
+			j, _ := json.Marshal(&errs)
+			n = len(j)
+		}
+		b.ReportMetric(float64(n), "bytes/op")
+	})
+
+	for _, c := range binaryCodecs {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var n int
+			for i := 0; i < b.N; i++ {
+				enc, _ := c.marshalErrors(&errs)
+				n = len(enc)
+			}
+			b.ReportMetric(float64(n), "bytes/op")
+		})
+	}
+}
+
+func BenchmarkDecodeErrors(b *testing.B) {
+	errs := benchmarkErrors()
+	j, _ := json.Marshal(&errs)
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var got Errors
+			if err := json.Unmarshal(j, &got); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	for _, c := range binaryCodecs {
+		enc, _ := c.marshalErrors(&errs)
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var got Errors
+				if err := c.unmarshalErrors(&got, enc); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
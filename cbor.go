@@ -0,0 +1,324 @@
+package jerrors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// CBOR major types, RFC 8949 section 3.1.
+const (
+	cborUint = iota
+	cborNegInt
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// CBOR tags for times: an RFC 3339 string, seconds since the epoch, and the extended time of
+// RFC 9581 used for times with fractional seconds.
+const (
+	cborTagTimeString   = 0
+	cborTagTimeEpoch    = 1
+	cborTagTimeExtended = 1001
+)
+
+// MarshalCBOR encodes the Error as CBOR (RFC 8949). Time is written as a tag 1 epoch time, or a
+// tag 1001 extended time (RFC 9581) if it has fractional seconds, so nanoseconds are kept.
+func (e Error) MarshalCBOR() ([]byte, error) {
+	w := &cborEncoder{}
+	encodeError(w, e)
+	return w.b, nil
+}
+
+// UnmarshalCBOR decodes an Error written by MarshalCBOR.
+func (e *Error) UnmarshalCBOR(b []byte) error {
+	return unmarshalBinary(&cborDecoder{binaryReader{b}}, func(r binaryDecoder) error { return decodeError(r, e) })
+}
+
+// MarshalCBOR encodes the Errors, its groups and all of their Errors as CBOR.
+func (e *Errors) MarshalCBOR() ([]byte, error) {
+	w := &cborEncoder{}
+	encodeErrors(w, e)
+	return w.b, nil
+}
+
+// UnmarshalCBOR decodes an Errors written by MarshalCBOR, linking each group to its parent.
+func (e *Errors) UnmarshalCBOR(b []byte) error {
+	return unmarshalBinary(&cborDecoder{binaryReader{b}}, func(r binaryDecoder) error { return decodeErrors(r, e, 0) })
+}
+
+type cborEncoder struct {
+	b []byte
+}
+
+// head writes the initial byte of an item with its argument in the fewest bytes.
+func (w *cborEncoder) head(major byte, v uint64) {
+	m := major << 5
+	switch {
+	case v < 24:
+		w.b = append(w.b, m|byte(v))
+	case v <= math.MaxUint8:
+		w.b = append(w.b, m|24, byte(v))
+	case v <= math.MaxUint16:
+		w.b = binary.BigEndian.AppendUint16(append(w.b, m|25), uint16(v))
+	case v <= math.MaxUint32:
+		w.b = binary.BigEndian.AppendUint32(append(w.b, m|26), uint32(v))
+	default:
+		w.b = binary.BigEndian.AppendUint64(append(w.b, m|27), v)
+	}
+}
+
+func (w *cborEncoder) writeMap(n int)     { w.head(cborMap, uint64(n)) }
+func (w *cborEncoder) writeArray(n int)   { w.head(cborArray, uint64(n)) }
+func (w *cborEncoder) writeUint(v uint64) { w.head(cborUint, v) }
+
+func (w *cborEncoder) writeInt(v int64) {
+	if v < 0 {
+		w.head(cborNegInt, uint64(-1-v))
+		return
+	}
+
+	w.head(cborUint, uint64(v))
+}
+
+func (w *cborEncoder) writeString(s string) {
+	w.head(cborText, uint64(len(s)))
+	w.b = append(w.b, s...)
+}
+
+func (w *cborEncoder) writeBool(v bool) {
+	if v {
+		w.b = append(w.b, 0xf5)
+		return
+	}
+
+	w.b = append(w.b, 0xf4)
+}
+
+func (w *cborEncoder) writeTime(t time.Time) {
+	if t.Nanosecond() == 0 {
+		w.head(cborTag, cborTagTimeEpoch)
+		w.writeInt(t.Unix())
+		return
+	}
+
+	w.head(cborTag, cborTagTimeExtended)
+	w.writeMap(2)
+	w.writeInt(1)
+	w.writeInt(t.Unix())
+	w.writeInt(-9)
+	w.writeInt(int64(t.Nanosecond()))
+}
+
+type cborDecoder struct {
+	binaryReader
+}
+
+// head reads the initial byte of an item and its argument. For simple values and floats the
+// argument is the value's bits.
+func (r *cborDecoder) head() (major byte, v uint64, err error) {
+	c, err := r.next()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	major, info := c>>5, c&0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info <= 27:
+		v, err = r.uint(1 << (info - 24))
+		return major, v, err
+	case info == 31:
+		return 0, 0, fmt.Errorf("jerrors: cbor: indefinite length items are not supported")
+	default:
+		return 0, 0, fmt.Errorf("jerrors: cbor: invalid initial byte 0x%02x", c)
+	}
+}
+
+// expect reads the head of an item of the given major type.
+func (r *cborDecoder) expect(major byte, name string) (uint64, error) {
+	m, v, err := r.head()
+	if err != nil {
+		return 0, err
+	}
+
+	if m != major {
+		return 0, fmt.Errorf("jerrors: cbor: expected %s, got major type %d", name, m)
+	}
+
+	return v, nil
+}
+
+func (r *cborDecoder) readMap() (int, error)     { return r.readLen(cborMap, "map") }
+func (r *cborDecoder) readArray() (int, error)   { return r.readLen(cborArray, "array") }
+func (r *cborDecoder) readUint() (uint64, error) { return r.expect(cborUint, "unsigned integer") }
+
+func (r *cborDecoder) readLen(major byte, name string) (int, error) {
+	n, err := r.expect(major, name)
+	if err != nil {
+		return 0, err
+	}
+
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf("jerrors: cbor: %s too long", name)
+	}
+
+	return int(n), nil
+}
+
+func (r *cborDecoder) readInt() (int64, error) {
+	m, v, err := r.head()
+	if err != nil {
+		return 0, err
+	}
+
+	if (m != cborUint && m != cborNegInt) || v > math.MaxInt64 {
+		return 0, fmt.Errorf("jerrors: cbor: expected integer, got major type %d", m)
+	}
+
+	if m == cborNegInt {
+		return -1 - int64(v), nil
+	}
+
+	return int64(v), nil
+}
+
+func (r *cborDecoder) readString() (string, error) {
+	n, err := r.expect(cborText, "text string")
+	if err != nil {
+		return "", err
+	}
+
+	b, err := r.take(n)
+	return string(b), err
+}
+
+func (r *cborDecoder) readBool() (bool, error) {
+	c, err := r.next()
+	if err != nil {
+		return false, err
+	}
+
+	switch c {
+	case 0xf4:
+		return false, nil
+	case 0xf5:
+		return true, nil
+	}
+
+	return false, fmt.Errorf("jerrors: cbor: expected bool, got 0x%02x", c)
+}
+
+// readTime reads a tag 0 RFC 3339 time, a tag 1 epoch time as an integer or float, or a tag
+// 1001 extended time with seconds and milli, micro or nanoseconds.
+func (r *cborDecoder) readTime() (time.Time, error) {
+	tag, err := r.expect(cborTag, "time tag")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch tag {
+	case cborTagTimeString:
+		s, err := r.readString()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case cborTagTimeEpoch:
+		if len(r.b) > 0 && (r.b[0] == 0xfa || r.b[0] == 0xfb) {
+			float := r.b[0]
+			_, bits, err := r.head()
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			f := math.Float64frombits(bits)
+			if float == 0xfa {
+				f = float64(math.Float32frombits(uint32(bits)))
+			}
+			sec, frac := math.Modf(f)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		}
+
+		sec, err := r.readInt()
+		return time.Unix(sec, 0), err
+	case cborTagTimeExtended:
+		return r.readExtendedTime()
+	}
+
+	return time.Time{}, fmt.Errorf("jerrors: cbor: unsupported time tag %d", tag)
+}
+
+func (r *cborDecoder) readExtendedTime() (time.Time, error) {
+	n, err := readLen(r, r.readMap)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var sec, nsec int64
+	for i := 0; i < n; i++ {
+		key, err := r.readInt()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		v, err := r.readInt()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		switch key {
+		case 1:
+			sec = v
+		case -3:
+			nsec = v * int64(time.Millisecond)
+		case -6:
+			nsec = v * int64(time.Microsecond)
+		case -9:
+			nsec = v
+		}
+	}
+
+	return time.Unix(sec, nsec), nil
+}
+
+func (r *cborDecoder) skip() error { return r.skipNested(0) }
+
+// skipNested skips the next item, which is depth items deep in the value being skipped.
+func (r *cborDecoder) skipNested(depth int) error {
+	if depth > maxBinaryDepth {
+		return errBinaryDepth
+	}
+
+	m, v, err := r.head()
+	if err != nil {
+		return err
+	}
+
+	switch m {
+	case cborBytes, cborText:
+		_, err = r.take(v)
+	case cborArray, cborMap:
+		if m == cborMap {
+			v *= 2
+		}
+
+		if v > uint64(len(r.b)) {
+			return fmt.Errorf("jerrors: cbor: %d items do not fit in %d bytes", v, len(r.b))
+		}
+
+		for i := uint64(0); i < v && err == nil; i++ {
+			err = r.skipNested(depth + 1)
+		}
+	case cborTag:
+		err = r.skipNested(depth + 1)
+	}
+
+	return err
+}
@@ -0,0 +1,179 @@
+package jerrors
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// CauseKey is the Metadata key From stores the message of the innermost error err wraps in, such
+// as "no such file or directory".
+const CauseKey = "cause"
+
+// Classifier sets the Level, code and Metadata of e, the Error From made for err. It returns
+// false, leaving e alone, if it does not apply to err.
+type Classifier func(err error, e *Error) bool
+
+var (
+	classifiers   = DefaultClassifiers()
+	classifiersMu sync.RWMutex
+)
+
+// SetClassifiers sets the Classifiers used by From. Pass nil to use none.
+func SetClassifiers(cs []Classifier) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+
+	classifiers = append([]Classifier{}, cs...)
+}
+
+// GetClassifiers returns a copy of the Classifiers used by From.
+func GetClassifiers() []Classifier {
+	classifiersMu.RLock()
+	defer classifiersMu.RUnlock()
+
+	return append([]Classifier{}, classifiers...)
+}
+
+// RegisterClassifier adds Classifiers to those used by From. The last ones registered are tried
+// first, so they can override the built in ones.
+func RegisterClassifier(cs ...Classifier) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+
+	classifiers = append(classifiers, cs...)
+}
+
+// DefaultClassifiers returns the built in Classifiers:
+//
+//	context.Canceled          WARN  canceled
+//	context.DeadlineExceeded  ERROR deadline_exceeded
+//	sql.ErrNoRows             WARN  not_found
+//	*os.PathError             ERROR path_error      op, path
+//	*net.OpError              ERROR network_error   op, net, addr
+//	*json.SyntaxError         ERROR invalid_json    offset
+//	*strconv.NumError         ERROR invalid_number  func, num
+func DefaultClassifiers() []Classifier {
+	return []Classifier{
+		ClassifyIs(context.Canceled, WARN, "canceled"),
+		ClassifyIs(context.DeadlineExceeded, ERROR, "deadline_exceeded"),
+		ClassifyIs(sql.ErrNoRows, WARN, "not_found"),
+		ClassifyAs(ERROR, "path_error", func(err *os.PathError) []interface{} {
+			return []interface{}{"op", err.Op, "path", err.Path}
+		}),
+		ClassifyAs(ERROR, "network_error", func(err *net.OpError) []interface{} {
+			args := []interface{}{"op", err.Op, "net", err.Net}
+			if err.Addr != nil {
+				args = append(args, "addr", err.Addr.String())
+			}
+			return args
+		}),
+		ClassifyAs(ERROR, "invalid_json", func(err *json.SyntaxError) []interface{} {
+			return []interface{}{"offset", err.Offset}
+		}),
+		ClassifyAs(ERROR, "invalid_number", func(err *strconv.NumError) []interface{} {
+			return []interface{}{"func", err.Func, "num", err.Num}
+		}),
+	}
+}
+
+// ClassifyIs returns a Classifier for errors matching target with errors.Is.
+func ClassifyIs(target error, level Level, code string, args ...interface{}) Classifier {
+	return ClassifyFunc(func(err error) bool { return errors.Is(err, target) }, level, code, args...)
+}
+
+// ClassifyFunc returns a Classifier for errors match returns true for.
+func ClassifyFunc(match func(error) bool, level Level, code string, args ...interface{}) Classifier {
+	return func(err error, e *Error) bool {
+		if !match(err) {
+			return false
+		}
+
+		e.Level = level
+		e.setMetadata(CodeKey, code)
+		e.AddMetadata(args...)
+		return true
+	}
+}
+
+// ClassifyAs returns a Classifier for errors wrapping a T, found with errors.As. metadata, if not
+// nil, returns key value pairs taken from the T to add to the Error's Metadata.
+// Example:
+//
+//	jerrors.RegisterClassifier(jerrors.ClassifyAs(jerrors.WARN, "http_status", func(err *StatusError) []interface{} {
+//		return []interface{}{"status", err.Code}
+//	}))
+func ClassifyAs[T error](level Level, code string, metadata func(T) []interface{}) Classifier {
+	return func(err error, e *Error) bool {
+		var target T
+		if !errors.As(err, &target) {
+			return false
+		}
+
+		e.Level = level
+		e.setMetadata(CodeKey, code)
+		if metadata != nil {
+			e.AddMetadata(metadata(target)...)
+		}
+		return true
+	}
+}
+
+// From converts err into an Error. If err wraps an Error, a Clone of it is returned. Otherwise
+// the Error is an ERROR with err's message, and the message of the innermost error it wraps in
+// Metadata[CauseKey], changed by the first Classifier that applies, newest first. Temporary,
+// Retryable and RetryAfter are set with Classify. The Error is counted by Metrics once it is
+// classified. Returns an empty Error if err is nil.
+func From(err error) Error {
+	if err == nil {
+		return Error{}
+	}
+
+	var je Error
+	if errors.As(err, &je) {
+		return je.Clone()
+	}
+
+	e := newError(ERROR, err.Error())
+	if c := cause(err).Error(); c != e.Message {
+		e.setMetadata(CauseKey, c)
+	}
+
+	e.Classify(err)
+	cs := GetClassifiers()
+	for i := len(cs) - 1; i >= 0; i-- {
+		if cs[i](err, &e) {
+			break
+		}
+	}
+
+	countCreated(e)
+	return e
+}
+
+// cause returns the innermost error err wraps, following the first error of joined errors.
+func cause(err error) error {
+	for {
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			next := u.Unwrap()
+			if next == nil {
+				return err
+			}
+			err = next
+		case interface{ Unwrap() []error }:
+			errs := u.Unwrap()
+			if len(errs) == 0 {
+				return err
+			}
+			err = errs[0]
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package jerrors
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromBuiltIn(t *testing.T) {
+	SetConfig(DefaultConfig())
+	missing := filepath.Join(t.TempDir(), "missing.conf")
+	_, openErr := os.Open(missing)
+	jsonErr := json.Unmarshal([]byte("{"), &struct{}{})
+	_, numErr := strconv.Atoi("ten")
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5432}, Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name  string
+		err   error
+		level Level
+		want  map[string]string
+	}{
+		{"canceled", fmt.Errorf("query users: %w", context.Canceled), WARN, map[string]string{CodeKey: "canceled", CauseKey: "context canceled"}},
+		{"deadline", context.DeadlineExceeded, ERROR, map[string]string{CodeKey: "deadline_exceeded"}},
+		{"no rows", fmt.Errorf("get user 1: %w", sql.ErrNoRows), WARN, map[string]string{CodeKey: "not_found", CauseKey: sql.ErrNoRows.Error()}},
+		{"path", openErr, ERROR, map[string]string{CodeKey: "path_error", CauseKey: "no such file or directory", "op": "open", "path": missing}},
+		{"net", netErr, ERROR, map[string]string{CodeKey: "network_error", CauseKey: "connection refused", "op": "dial", "net": "tcp", "addr": "127.0.0.1:5432"}},
+		{"json", jsonErr, ERROR, map[string]string{CodeKey: "invalid_json", "offset": "1"}},
+		{"number", numErr, ERROR, map[string]string{CodeKey: "invalid_number", CauseKey: strconv.ErrSyntax.Error(), "func": "Atoi", "num": "ten"}},
+		{"unknown", errors.New("boom"), ERROR, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := From(tt.err)
+			require.Equal(t, tt.level, e.Level)
+			require.Equal(t, tt.err.Error(), e.Message)
+			require.Equal(t, tt.want, e.Metadata)
+		})
+	}
+}
+
+func TestFromClassify(t *testing.T) {
+	e := From(fmt.Errorf("query: %w", context.DeadlineExceeded))
+	require.True(t, e.Temporary)
+	require.True(t, e.Retryable)
+
+	e = From(context.Canceled)
+	require.False(t, e.Retryable)
+}
+
+func TestFromError(t *testing.T) {
+	require.True(t, From(nil).Equal(Error{}))
+
+	orig := NewError(WARN, testMessage, CodeKey, "custom")
+	e := From(fmt.Errorf("wrapped: %w", orig))
+	require.True(t, orig.Equal(e))
+
+	e.AddMetadata("extra", "1")
+	require.NotContains(t, orig.Metadata, "extra")
+}
+
+type statusError struct{ status int }
+
+func (e *statusError) Error() string { return "status " + strconv.Itoa(e.status) }
+
+func TestRegisterClassifier(t *testing.T) {
+	defer SetClassifiers(DefaultClassifiers())
+
+	RegisterClassifier(
+		ClassifyAs(WARN, "http_status", func(err *statusError) []interface{} {
+			return []interface{}{"status", err.status}
+		}),
+		ClassifyFunc(func(err error) bool { return strings.HasPrefix(err.Error(), "quota") }, FATAL, "quota", "team", "ops"),
+		ClassifyIs(context.Canceled, INFO, "stopped"),
+	)
+
+	e := From(fmt.Errorf("fetch: %w", &statusError{503}))
+	require.Equal(t, WARN, e.Level)
+	require.Equal(t, map[string]string{CodeKey: "http_status", CauseKey: "status 503", "status": "503"}, e.Metadata)
+
+	e = From(errors.New("quota exceeded"))
+	require.Equal(t, FATAL, e.Level)
+	require.Equal(t, map[string]string{CodeKey: "quota", "team": "ops"}, e.Metadata)
+
+	// Later Classifiers override the built in ones.
+	e = From(context.Canceled)
+	require.Equal(t, INFO, e.Level)
+	require.Equal(t, "stopped", e.Code())
+
+	SetClassifiers(nil)
+	require.Empty(t, GetClassifiers())
+	e = From(context.Canceled)
+	require.Equal(t, ERROR, e.Level)
+	require.Equal(t, "", e.Code())
+}
+
+func TestFromCaller(t *testing.T) {
+	c := DefaultConfig()
+	c.LogCaller = true
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	require.Contains(t, From(errors.New("boom")).Metadata["caller"], "TestFromCaller")
+	require.Contains(t, NewError(ERROR, "boom").Metadata["caller"], "TestFromCaller")
+}
@@ -0,0 +1,217 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Time formats for Config.TimeFormat, matched ignoring case. Any other value is used as a
+// time.Format layout.
+const (
+	TimeRFC3339     = "rfc3339"
+	TimeRFC3339Nano = "rfc3339nano"
+	TimeUnix        = "unix"
+	TimeUnixMilli   = "unixmilli"
+	TimeUnixNano    = "unixnano"
+)
+
+// Clock provides the current time for new Errors, rotation and sampling.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock used by default. It returns time.Now.
+type SystemClock struct{}
+
+// Now returns time.Now.
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// clockHolder keeps the concrete type stored in activeClock constant.
+type clockHolder struct{ Clock }
+
+// activeClock is set when declared, like config, so package level Errors can be created before
+// init runs.
+var activeClock = newClockValue(SystemClock{})
+
+func newClockValue(c Clock) *atomic.Value {
+	v := new(atomic.Value)
+	v.Store(clockHolder{c})
+	return v
+}
+
+// SetClock sets the Clock used for every Error created. Pass nil to restore SystemClock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = SystemClock{}
+	}
+
+	activeClock.Store(clockHolder{c})
+}
+
+// GetClock returns the active Clock.
+func GetClock() Clock { return activeClock.Load().(clockHolder).Clock }
+
+// now returns the current time from the active Clock. It is only used to stamp new Errors, so a
+// ManualClock Step advances once per Error.
+func now() time.Time { return GetClock().Now() }
+
+// peeker is implemented by Clocks that can report the time without advancing it.
+type peeker interface {
+	peek() time.Time
+}
+
+// clockTime returns the current time from the active Clock without advancing a ManualClock. It is
+// used by rotation, sampling and syslog so they do not change the times given to new Errors.
+func clockTime() time.Time {
+	c := GetClock()
+	if p, ok := c.(peeker); ok {
+		return p.peek()
+	}
+
+	return c.Now()
+}
+
+// ManualClock is a Clock for tests. It returns a frozen time that only changes when Set or Add
+// is called, or advances by Step after every call to Now. jerrors only calls Now when it stamps a
+// new Error, so with a Step every Error created gets the next time, however much rotation,
+// sampling or syslog ran in between.
+type ManualClock struct {
+	mu   sync.Mutex
+	t    time.Time
+	step time.Duration
+}
+
+// NewManualClock creates a ManualClock frozen at t.
+func NewManualClock(t time.Time) *ManualClock { return &ManualClock{t: t} }
+
+// Now returns the clock's time, then advances it by Step.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := c.t
+	c.t = c.t.Add(c.step)
+	return t
+}
+
+// peek returns the clock's time without advancing it.
+func (c *ManualClock) peek() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t
+}
+
+// Set changes the clock's time to t.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = t
+}
+
+// Add moves the clock's time forward by d.
+func (c *ManualClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = c.t.Add(d)
+}
+
+// Step sets how far the clock advances after every call to Now. 0 freezes the clock.
+func (c *ManualClock) Step(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.step = d
+}
+
+// timeFormats lists the Time format names.
+var timeFormats = []string{TimeRFC3339, TimeRFC3339Nano, TimeUnix, TimeUnixMilli, TimeUnixNano}
+
+// timeFormatName returns the Time format name matching f ignoring case, so "RFC3339" is
+// TimeRFC3339. Returns f and false if it is not a name.
+func timeFormatName(f string) (string, bool) {
+	for _, name := range timeFormats {
+		if strings.EqualFold(f, name) {
+			return name, true
+		}
+	}
+
+	return f, false
+}
+
+// validTimeLayout returns true if layout has at least one time.Format element and a time written
+// with it parses back with it.
+func validTimeLayout(layout string) bool {
+	s := time.Date(2001, 11, 22, 13, 14, 15, 0, time.UTC).Format(layout)
+	if s == layout {
+		return false
+	}
+
+	_, err := time.Parse(layout, s)
+	return err == nil
+}
+
+// parseTime converts JSON written by appendTime back to a time. Strings are parsed as RFC 3339 or
+// with the Config.TimeFormat layout. Numbers use the Config.TimeFormat unit, or are guessed from
+// their size if TimeFormat is not a unix format.
+func parseTime(b []byte) (time.Time, error) {
+	format := config.Load().TimeFormat
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err == nil {
+			return t, nil
+		}
+
+		switch format {
+		case "", TimeRFC3339, TimeRFC3339Nano, TimeUnix, TimeUnixMilli, TimeUnixNano:
+			return time.Time{}, err
+		}
+
+		return time.Parse(format, s)
+	}
+
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("jerrors: invalid time %s", b)
+	}
+
+	unit := format
+	switch unit {
+	case TimeUnix, TimeUnixMilli, TimeUnixNano:
+	default:
+		unit = guessUnixUnit(n)
+	}
+
+	switch unit {
+	case TimeUnix:
+		return time.Unix(n, 0), nil
+	case TimeUnixMilli:
+		return time.UnixMilli(n), nil
+	default:
+		return time.Unix(0, n), nil
+	}
+}
+
+// guessUnixUnit picks seconds, milliseconds or nanoseconds from the size of a unix timestamp.
+func guessUnixUnit(n int64) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch {
+	case n < 1e11:
+		return TimeUnix
+	case n < 1e14:
+		return TimeUnixMilli
+	default:
+		return TimeUnixNano
+	}
+}
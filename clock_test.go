@@ -0,0 +1,111 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2024, 7, 18, 13, 9, 25, 355507403, time.FixedZone("EDT", -4*60*60))
+
+func setTestClock(t *testing.T, now time.Time) *ManualClock {
+	c := NewManualClock(now)
+	SetClock(c)
+	t.Cleanup(func() { SetClock(nil) })
+	return c
+}
+
+func TestClockManualClock(t *testing.T) {
+	c := NewManualClock(testTime)
+	require.Equal(t, testTime, c.Now())
+	require.Equal(t, testTime, c.Now())
+
+	c.Add(time.Second)
+	require.Equal(t, testTime.Add(time.Second), c.Now())
+
+	c.Set(testTime)
+	c.Step(time.Millisecond)
+	require.Equal(t, testTime, c.Now())
+	require.Equal(t, testTime.Add(time.Millisecond), c.Now())
+}
+
+func TestClockSetClock(t *testing.T) {
+	SetConfig(DefaultConfig())
+	c := setTestClock(t, testTime)
+	c.Step(time.Second)
+
+	e1 := NewError(ERROR, testMessage)
+
+	// Sampling and syslog read the clock without stepping it.
+	s := NewSampler(SamplerConfig{Default: SamplePolicy{First: 10}})
+	s.Allow(e1)
+	s.Flush(false)
+	(&Syslog{config: DefaultSyslogConfig()}).Format(Error{Message: testMessage})
+
+	e2 := NewError(ERROR, testMessage)
+	require.Equal(t, testTime, *e1.Time)
+	require.Equal(t, testTime.Add(time.Second), *e2.Time)
+
+	SetClock(nil)
+	require.IsType(t, SystemClock{}, GetClock())
+}
+
+func TestClockTimeFormat(t *testing.T) {
+	setTestClock(t, testTime)
+	defer SetConfig(DefaultConfig())
+
+	tests := []struct {
+		format string
+		utc    bool
+		want   string
+	}{
+		{TimeRFC3339Nano, false, `"time":"2024-07-18T13:09:25.355507403-04:00"`},
+		{"", false, `"time":"2024-07-18T13:09:25.355507403-04:00"`},
+		{TimeRFC3339, false, `"time":"2024-07-18T13:09:25-04:00"`},
+		{TimeRFC3339Nano, true, `"time":"2024-07-18T17:09:25.355507403Z"`},
+		{TimeUnix, false, `"time":1721322565`},
+		{TimeUnixMilli, false, `"time":1721322565355`},
+		{TimeUnixNano, false, `"time":1721322565355507403`},
+		{"2006-01-02 15:04:05", true, `"time":"2024-07-18 17:09:25"`},
+	}
+
+	for _, tt := range tests {
+		c := DefaultConfig()
+		c.TimeFormat = tt.format
+		c.UTC = tt.utc
+		SetConfig(c)
+
+		err := NewError(ERROR, testMessage)
+		s := err.String()
+		require.Contains(t, s, tt.want, tt.format)
+
+		// Round trip.
+		var got Error
+		require.Nil(t, json.Unmarshal([]byte(s), &got), tt.format)
+		require.NotNil(t, got.Time, tt.format)
+		require.True(t, got.Time.Round(time.Second).Equal(testTime.Round(time.Second)), tt.format)
+	}
+}
+
+func TestClockParseTime(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	// Unix times are guessed by size when TimeFormat is not a unix format.
+	for _, j := range []string{`1721322565`, `1721322565355`, `1721322565355507403`} {
+		got, err := parseTime([]byte(j))
+		require.Nil(t, err)
+		require.Equal(t, int64(1721322565), got.Unix(), j)
+	}
+
+	_, err := parseTime([]byte(`"yesterday"`))
+	require.Error(t, err)
+
+	_, err = parseTime([]byte(`true`))
+	require.Error(t, err)
+
+	var e Error
+	require.Nil(t, json.Unmarshal([]byte(`{"message":"no time"}`), &e))
+	require.Nil(t, e.Time)
+}
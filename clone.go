@@ -0,0 +1,78 @@
+package jerrors
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of the Error. Changing the copy's Time, Field, Position or Metadata
+// does not change the original.
+func (e Error) Clone() Error {
+	if e.Time != nil {
+		t := *e.Time
+		e.Time = &t
+	}
+
+	if e.Position != nil {
+		p := *e.Position
+		e.Position = &p
+	}
+
+	e.Field = slices.Clone(e.Field)
+	e.Metadata = maps.Clone(e.Metadata)
+	return e
+}
+
+// With returns a copy of the Error with args added to its Metadata. args should be in the form
+// of keyString1, valueString1,...
+// Example:
+// base := jerrors.NewError(jerrors.ERROR, "query failed", "db", "users")
+// err := base.With("id", 42).WithLevel(jerrors.WARN)
+func (e Error) With(args ...interface{}) Error {
+	c := e.Clone()
+	if c.Metadata == nil {
+		c.Metadata = make(map[string]string)
+	}
+
+	c.addMetadata(args...)
+	return c
+}
+
+// WithLevel returns a copy of the Error with its Level set to level.
+func (e Error) WithLevel(level Level) Error {
+	c := e.Clone()
+	c.Level = level
+	return c
+}
+
+// WithMessage returns a copy of the Error with its Message set to msg.
+func (e Error) WithMessage(msg string) Error {
+	c := e.Clone()
+	c.Message = msg
+	return c
+}
+
+// Is reports whether the Error is a copy of the package sentinel target, so errors.Is works with
+// sentinels returned as an error, such as errors.Is(err, jerrors.ErrNoErrorFound). Other Errors
+// only match themselves through errors.Is.
+func (e Error) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && t.sentinel != nil && e.sentinel == t.sentinel
+}
+
+// Clone returns a deep copy of the Errors and its groups. Changing the copy or its Errors does not
+// change the original. The copy has no parent.
+func (e *Errors) Clone() Errors { return *e.clone(nil) }
+
+func (e *Errors) clone(parent *Errors) *Errors {
+	c := &Errors{Name: e.Name, Errors: make([]Error, len(e.Errors)), Level: e.Level, parent: parent}
+	for i, err := range e.Errors {
+		c.Errors[i] = err.Clone()
+	}
+
+	for _, g := range e.Groups {
+		c.Groups = append(c.Groups, g.clone(c))
+	}
+
+	return c
+}
@@ -0,0 +1,109 @@
+package jerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneAddMetadataCopyOnWrite(t *testing.T) {
+	SetConfig(DefaultConfig())
+	e1 := NewError(ERROR, testMessage, "key1", "val1")
+	e2 := e1
+	e2.AddMetadata("key2", "val2")
+
+	require.NotContains(t, e1.Metadata, "key2")
+	require.Equal(t, "val2", e2.Metadata["key2"])
+
+	e3 := e1.With("key3", "val3")
+	require.NotContains(t, e1.Metadata, "key3")
+	require.Equal(t, "val3", e3.Metadata["key3"])
+
+	// SetMetadata and DeleteMetadata on a copy leave the original alone, Lazy values included.
+	e4 := e1
+	require.NoError(t, e4.SetMetadata("key1", Lazy(func() any { return "lazy" })))
+	require.Equal(t, "val1", e1.Metadata["key1"])
+	e5 := e1
+	e5.DeleteMetadata("key1")
+	require.Equal(t, "val1", e1.Metadata["key1"])
+	require.Equal(t, []string{"key1"}, e1.MetadataKeys())
+
+	var empty Error
+	empty.AddMetadata("key", "val")
+	require.Equal(t, map[string]string{"key": "val"}, empty.Metadata)
+}
+
+func TestCloneError(t *testing.T) {
+	SetConfig(DefaultConfig())
+	e := NewError(ERROR, testMessage, "key", "val")
+	e.Field = FieldPath{{Name: "port"}}
+
+	c := e.Clone()
+	require.True(t, e.Equal(c))
+	require.Equal(t, *e.Time, *c.Time)
+
+	c.Metadata["key"] = "changed"
+	c.Field[0].Name = "host"
+	*c.Time = c.Time.Add(1)
+	require.Equal(t, "val", e.Metadata["key"])
+	require.Equal(t, "port", e.Field[0].Name)
+	require.NotEqual(t, *e.Time, *c.Time)
+}
+
+func TestCloneWith(t *testing.T) {
+	SetConfig(DefaultConfig())
+	base := NewError(ERROR, testMessage, "db", "users")
+
+	e := base.With("id", 42).WithLevel(WARN).WithMessage("other message")
+	require.Equal(t, WARN, e.Level)
+	require.Equal(t, "other message", e.Message)
+	require.Equal(t, "42", e.Metadata["id"])
+	require.Equal(t, "users", e.Metadata["db"])
+
+	require.Equal(t, ERROR, base.Level)
+	require.Equal(t, testMessage, base.Message)
+	require.NotContains(t, base.Metadata, "id")
+
+	var empty Error
+	require.Equal(t, map[string]string{"key": "val"}, empty.With("key", "val").Metadata)
+	require.Nil(t, empty.Metadata)
+}
+
+func TestCloneErrors(t *testing.T) {
+	SetConfig(DefaultConfig())
+	errs := New()
+	errs.NewError(ERROR, testMessage, "key", "val")
+
+	c := errs.Clone()
+	require.True(t, errs.Equal(c))
+
+	c.Errors[0].Metadata["key"] = "changed"
+	c.NewError(FATAL, testMessage)
+	require.Equal(t, "val", errs.Errors[0].Metadata["key"])
+	require.Len(t, errs.Errors, 1)
+	require.Equal(t, ERROR, errs.Level)
+}
+
+func TestCloneSentinel(t *testing.T) {
+	errs := New()
+	first := errs.First()
+	first.AddMetadata("key", "val")
+	first.Message = "changed"
+
+	require.Equal(t, ErrNoErrorFound, errs.First())
+	require.Equal(t, ErrNoErrorFound, errs.Last())
+	require.NotContains(t, ErrNoErrorFound.Metadata, "key")
+	require.Panics(t, func() { ErrNoErrorFound.Metadata["key"] = "val" })
+
+	var err error = errs.Last()
+	require.True(t, errors.Is(fmt.Errorf("lookup: %w", err), ErrNoErrorFound))
+	require.True(t, errors.Is(first, ErrNoErrorFound), "changed copies still match")
+	require.False(t, errors.Is(NewError(ERROR, testMessage), ErrNoErrorFound))
+	require.False(t, errors.Is(Error{Message: "No error found"}, ErrNoErrorFound))
+
+	// Other Errors are not matched by equality.
+	a := NewError(ERROR, testMessage)
+	require.False(t, errors.Is(a, NewError(ERROR, testMessage)))
+}
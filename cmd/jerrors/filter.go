@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chadeldridge/jerrors"
+)
+
+// filter selects Errors by level, time, message and metadata.
+type filter struct {
+	level string
+	since string
+	until string
+	grep  string
+	meta  metaFlags
+
+	min  jerrors.Level
+	from time.Time
+	to   time.Time
+	re   *regexp.Regexp
+}
+
+// register adds the filter flags to fs.
+func (f *filter) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.level, "level", "", "only show errors at or above `level` (debug, info, warn, error, fatal)")
+	fs.StringVar(&f.since, "since", "", "only show errors at or after `time`, as RFC 3339, a date or a duration ago such as 1h")
+	fs.StringVar(&f.until, "until", "", "only show errors before `time`, in the same formats as -since")
+	fs.StringVar(&f.grep, "grep", "", "only show errors whose message matches `regexp`")
+	fs.Var(&f.meta, "meta", "only show errors with metadata `key=value`, or just key to require it. Repeatable")
+}
+
+// compile checks the flag values. Relative times are taken from now.
+func (f *filter) compile(now time.Time) error {
+	if f.level != "" {
+		if f.min = jerrors.GetLevel(f.level); f.min == 0 {
+			return fmt.Errorf("invalid -level %q", f.level)
+		}
+	}
+
+	var err error
+	if f.from, err = parseTimeArg(f.since, now); err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+
+	if f.to, err = parseTimeArg(f.until, now); err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+
+	if f.grep != "" {
+		if f.re, err = regexp.Compile(f.grep); err != nil {
+			return fmt.Errorf("invalid -grep: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// match reports whether e passes every filter. Errors without a Time never pass a time filter.
+func (f *filter) match(e jerrors.Error) bool {
+	if e.Level < f.min {
+		return false
+	}
+
+	if !f.from.IsZero() || !f.to.IsZero() {
+		if e.Time == nil {
+			return false
+		}
+
+		if !f.from.IsZero() && e.Time.Before(f.from) {
+			return false
+		}
+
+		if !f.to.IsZero() && !e.Time.Before(f.to) {
+			return false
+		}
+	}
+
+	if f.re != nil && !f.re.MatchString(e.Message) {
+		return false
+	}
+
+	for _, m := range f.meta {
+		v, ok := e.Metadata[m.key]
+		if !ok || (m.hasValue && v != m.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseTimeArg parses s as an RFC 3339 time, a date, or a duration before now. Returns the zero
+// time if s is empty.
+func parseTimeArg(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a time, date or duration", s)
+}
+
+// metaMatch is a single -meta flag.
+type metaMatch struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// metaFlags collects repeated -meta flags.
+type metaFlags []metaMatch
+
+func (m *metaFlags) String() string {
+	s := make([]string, len(*m))
+	for i, mm := range *m {
+		s[i] = mm.key
+		if mm.hasValue {
+			s[i] += "=" + mm.value
+		}
+	}
+
+	return strings.Join(s, ",")
+}
+
+func (m *metaFlags) Set(s string) error {
+	key, value, hasValue := strings.Cut(s, "=")
+	if key == "" {
+		return fmt.Errorf("missing key in %q", s)
+	}
+
+	*m = append(*m, metaMatch{key: key, value: value, hasValue: hasValue})
+	return nil
+}
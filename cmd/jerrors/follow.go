@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// tailReader reads a file like tail -f. At the end of the file it waits for more data instead
+// of returning io.EOF, until ctx is done. A truncated file is read again from the start and a
+// file replaced by log rotation is reopened.
+type tailReader struct {
+	ctx    context.Context
+	name   string
+	file   *os.File
+	offset int64
+	poll   time.Duration
+}
+
+func (t *tailReader) Read(p []byte) (int, error) {
+	for {
+		n, err := t.file.Read(p)
+		t.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		if err := t.reopen(); err != nil {
+			return 0, err
+		}
+
+		select {
+		case <-t.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(t.poll):
+		}
+	}
+}
+
+// reopen starts over if the file was truncated or replaced. A missing file is waited for, since
+// it is expected in the middle of a rotation.
+func (t *tailReader) reopen() error {
+	info, err := os.Stat(t.name)
+	if err != nil {
+		return nil
+	}
+
+	cur, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(info, cur) {
+		f, err := os.Open(t.name)
+		if err != nil {
+			return nil
+		}
+
+		t.file.Close()
+		t.file, t.offset = f, 0
+		return nil
+	}
+
+	if info.Size() < t.offset {
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		t.offset = 0
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/chadeldridge/jerrors"
+)
+
+// prettyTimeFormat is the time layout used by the pretty format.
+const prettyTimeFormat = "2006-01-02 15:04:05.000"
+
+// formats maps the -format names to their formatters.
+var formats = map[string]func(jerrors.Error) string{
+	"pretty": formatPretty,
+	"logfmt": formatLogfmt,
+	"json":   formatJSON,
+}
+
+// formatPretty renders e as "time LEVEL message key=value ...".
+func formatPretty(e jerrors.Error) string {
+	var b strings.Builder
+	if e.Time != nil {
+		b.WriteString(e.Time.Format(prettyTimeFormat) + " ")
+	}
+
+	b.WriteString(padRight(strings.ToUpper(e.Level.String()), 5) + " " + e.Message)
+	for _, kv := range pairs(e) {
+		b.WriteString(" " + kv[0] + "=" + logfmtValue(kv[1]))
+	}
+
+	return b.String()
+}
+
+// formatLogfmt renders e as logfmt key=value pairs.
+func formatLogfmt(e jerrors.Error) string {
+	var kvs []string
+	if e.Time != nil {
+		kvs = append(kvs, "time="+e.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	}
+
+	if e.Level != 0 {
+		kvs = append(kvs, "level="+e.Level.String())
+	}
+
+	kvs = append(kvs, "msg="+logfmtValue(e.Message))
+	for _, kv := range pairs(e) {
+		kvs = append(kvs, logfmtKey(kv[0])+"="+logfmtValue(kv[1]))
+	}
+
+	return strings.Join(kvs, " ")
+}
+
+// formatJSON renders e as a single line of JSON, as written by Error.Log.
+func formatJSON(e jerrors.Error) string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+// pairs returns the fields of e other than Time, Level and Message as key value pairs, with the
+// Metadata in order.
+func pairs(e jerrors.Error) [][2]string {
+	var kvs [][2]string
+	if len(e.Field) > 0 {
+		kvs = append(kvs, [2]string{"field", e.Field.Pointer()})
+	}
+
+	if e.Position.IsValid() {
+		kvs = append(kvs, [2]string{"pos", e.Position.String()})
+	}
+
+	if e.Retryable {
+		kvs = append(kvs, [2]string{"retryable", "true"})
+	}
+
+	if e.Temporary {
+		kvs = append(kvs, [2]string{"temporary", "true"})
+	}
+
+	if e.RetryAfter > 0 {
+		kvs = append(kvs, [2]string{"retry_after", e.RetryAfter.String()})
+	}
+
+	for _, k := range e.MetadataKeys() {
+		kvs = append(kvs, [2]string{k, e.Metadata[k]})
+	}
+
+	return kvs
+}
+
+// logfmtKey replaces the characters logfmt keys cannot hold with '_'.
+func logfmtKey(k string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, k)
+}
+
+// logfmtValue quotes v if it is empty or holds spaces, quotes, '=' or control characters.
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\\") || strings.IndexFunc(v, func(r rune) bool { return r < ' ' }) >= 0 {
+		return strconv.Quote(v)
+	}
+
+	return v
+}
+
+func padRight(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+
+	return s + strings.Repeat(" ", n-len(s))
+}
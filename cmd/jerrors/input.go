@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chadeldridge/jerrors"
+)
+
+// input says where and how to read logs.
+type input struct {
+	names  []string
+	stdin  io.Reader
+	follow bool
+	poll   time.Duration
+}
+
+// read decodes every Error from the named files, or stdin if there are none or a name is "-",
+// and passes them to fn. Files are read in order, or all at once and followed until ctx is done
+// if follow is set. fn may be called from several goroutines when following.
+func (in input) read(ctx context.Context, fn func(jerrors.Error)) error {
+	names := in.names
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	if !in.follow {
+		for _, name := range names {
+			if err := in.readOne(ctx, name, fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		first error
+	)
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := in.readOne(ctx, name, fn); err != nil {
+				mu.Lock()
+				if first == nil {
+					first = err
+				}
+				mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	return first
+}
+
+func (in input) readOne(ctx context.Context, name string, fn func(jerrors.Error)) error {
+	var r io.Reader = in.stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+
+		if in.follow {
+			// The tailReader may reopen the file, so close whichever file it ends on.
+			t := &tailReader{ctx: ctx, name: name, file: f, poll: in.poll}
+			defer func() { t.file.Close() }()
+			r = t
+		} else {
+			defer f.Close()
+			r = f
+		}
+	}
+
+	d := jerrors.NewDecoder(r)
+	for {
+		errs, err := d.Decode()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, e := range errs {
+			fn(e)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Command jerrors views, filters, converts and summarizes logs written by jerrors.
+//
+// Usage:
+//
+//	jerrors [view] [flags] [file ...]
+//	jerrors summary [flags] [file ...]
+//	jerrors schema [-o file]
+//
+// Files are read in order. With no files, or a file named "-", stdin is read. Run
+// "jerrors help" for the list of commands and "jerrors <command> -h" for their flags.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+// env holds the standard streams so commands can be tested.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a jerrors subcommand.
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string, e env) int
+}
+
+// commands lists the subcommands. The first one runs when no command is given.
+var commands = []command{
+	{name: "view", usage: "filter logs and print them as pretty text, logfmt or JSON", run: runView},
+	{name: "summary", usage: "count errors by level, code or message and metadata", run: runSummary},
+	{name: "schema", usage: "print the JSON Schema of the log format", run: runSchema},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run runs the command named by args[0], or the default command, and returns the exit code.
+func run(ctx context.Context, args []string, e env) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			usage(e.stdout)
+			return 0
+		}
+
+		for _, c := range commands {
+			if args[0] == c.name {
+				return c.run(ctx, args[1:], e)
+			}
+		}
+	}
+
+	return commands[0].run(ctx, args, e)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: jerrors [command] [flags] [file ...]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintf(w, "\nWith no command, %s is run. Use \"jerrors <command> -h\" for its flags.\n", commands[0].name)
+}
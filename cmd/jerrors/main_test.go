@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chadeldridge/jerrors"
+	"github.com/stretchr/testify/require"
+)
+
+const testLog = `{"time":"2024-07-18T13:00:00Z","level":"info","message":"starting","metadata":{"host":"a"}}
+panic: not an error line
+{"time":"2024-07-18T13:05:00Z","level":"warn","message":"slow query","metadata":{"host":"a","table":"users"}}
+[{"time":"2024-07-18T13:10:00Z","level":"error","message":"disk full","field":"/mount","metadata":{"host":"b","mount":"/var lib"}},{"level":"error","message":"no time"}]
+failed: {"time":"2024-07-18T13:15:00Z","level":"fatal","message":"cannot start","metadata":{"host":"b"}}
+`
+
+func runTest(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, env{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr})
+	return stdout.String(), stderr.String(), code
+}
+
+func writeLog(t *testing.T, content string) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "errors.log")
+	require.Nil(t, os.WriteFile(name, []byte(content), 0o644))
+	return name
+}
+
+func TestViewPretty(t *testing.T) {
+	out, _, code := runTest(t, testLog)
+	require.Equal(t, 0, code)
+	require.Equal(t, `2024-07-18 13:00:00.000 INFO  starting host=a
+2024-07-18 13:05:00.000 WARN  slow query host=a table=users
+2024-07-18 13:10:00.000 ERROR disk full field=/mount host=b mount="/var lib"
+ERROR no time
+2024-07-18 13:15:00.000 FATAL cannot start host=b
+`, out)
+}
+
+func TestViewFormats(t *testing.T) {
+	out, _, code := runTest(t, testLog, "view", "-format", "logfmt", "-level", "fatal")
+	require.Equal(t, 0, code)
+	require.Equal(t, "time=2024-07-18T13:15:00.000Z level=fatal msg=\"cannot start\" host=b\n", out)
+
+	out, _, code = runTest(t, testLog, "-format", "json", "-level", "fatal")
+	require.Equal(t, 0, code)
+	require.Equal(t, `{"time":"2024-07-18T13:15:00Z","level":"fatal","message":"cannot start","metadata":{"host":"b"}}`+"\n", out)
+
+	_, stderr, code := runTest(t, testLog, "-format", "xml")
+	require.Equal(t, 2, code)
+	require.Contains(t, stderr, "json, logfmt, pretty")
+}
+
+func TestViewFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"level", []string{"-level", "error"}, []string{"disk full", "no time", "cannot start"}},
+		{"since", []string{"-since", "2024-07-18T13:05:00Z"}, []string{"slow query", "disk full", "cannot start"}},
+		{"until", []string{"-until", "2024-07-18T13:05:00Z"}, []string{"starting"}},
+		{"grep", []string{"-grep", "^(slow|disk)"}, []string{"slow query", "disk full"}},
+		{"meta value", []string{"-meta", "host=b"}, []string{"disk full", "cannot start"}},
+		{"meta key", []string{"-meta", "table"}, []string{"slow query"}},
+		{"combined", []string{"-meta", "host=b", "-level", "fatal"}, []string{"cannot start"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, code := runTest(t, testLog, append([]string{"-format", "json"}, tt.args...)...)
+			require.Equal(t, 0, code)
+
+			var got []string
+			d := jerrors.NewDecoder(strings.NewReader(out))
+			for errs, err := d.Decode(); err == nil; errs, err = d.Decode() {
+				got = append(got, errs[0].Message)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestViewBadFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"-level", "loud"},
+		{"-since", "yesterday"},
+		{"-grep", "("},
+		{"-meta", "=x"},
+		{"-nope"},
+	} {
+		_, stderr, code := runTest(t, "", args...)
+		require.Equal(t, 2, code, args)
+		require.NotEmpty(t, stderr, args)
+	}
+}
+
+func TestViewFiles(t *testing.T) {
+	name := writeLog(t, testLog)
+	out, _, code := runTest(t, `{"level":"info","message":"from stdin"}`, "-format", "logfmt", "-level", "fatal", name, "-")
+	require.Equal(t, 0, code)
+	require.Equal(t, "time=2024-07-18T13:15:00.000Z level=fatal msg=\"cannot start\" host=b\n", out)
+
+	out, _, code = runTest(t, `{"level":"info","message":"from stdin"}`, "-format", "logfmt", "-")
+	require.Equal(t, 0, code)
+	require.Equal(t, "level=info msg=\"from stdin\"\n", out)
+
+	_, stderr, code := runTest(t, "", filepath.Join(t.TempDir(), "missing.log"))
+	require.Equal(t, 1, code)
+	require.Contains(t, stderr, "missing.log")
+}
+
+func TestViewHelp(t *testing.T) {
+	out, _, code := runTest(t, "", "help")
+	require.Equal(t, 0, code)
+	require.Contains(t, out, "view")
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of follow mode.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestViewFollow(t *testing.T) {
+	name := writeLog(t, `{"level":"info","message":"first"}`+"\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stdout syncBuffer
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"-f", "-poll", "5ms", "-format", "logfmt", name}, env{stdout: &stdout, stderr: &stdout})
+	}()
+
+	waitFor := func(want string) {
+		t.Helper()
+		require.Eventually(t, func() bool { return strings.Contains(stdout.String(), want) }, 2*time.Second, 5*time.Millisecond)
+	}
+	waitFor("first")
+
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.Nil(t, err)
+	_, err = f.WriteString(`{"level":"warn","message":"second"}` + "\n")
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+	waitFor("second")
+
+	// Rotation: the file is moved away and recreated.
+	require.Nil(t, os.Rename(name, name+".1"))
+	require.Nil(t, os.WriteFile(name, []byte(`{"level":"error","message":"third"}`+"\n"), 0o644))
+	waitFor("third")
+
+	// Truncation: the file is read again from the start.
+	require.Nil(t, os.WriteFile(name, []byte(`{"level":"error","message":"4"}`+"\n"), 0o644))
+	waitFor("msg=4")
+
+	cancel()
+	select {
+	case code := <-done:
+		require.Equal(t, 0, code)
+	case <-time.After(2 * time.Second):
+		t.Fatal("follow did not stop")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/chadeldridge/jerrors"
+)
+
+// runSchema prints the JSON Schema of the jerrors wire format, or writes it to the -o file.
+func runSchema(ctx context.Context, args []string, e env) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: jerrors schema [-o file]")
+		fs.PrintDefaults()
+	}
+
+	out := fs.String("o", "", "write the schema to `file` instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *out == "" {
+		e.stdout.Write(jerrors.Schema())
+		return 0
+	}
+
+	if err := os.WriteFile(*out, jerrors.Schema(), 0o644); err != nil {
+		fmt.Fprintln(e.stderr, "jerrors:", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chadeldridge/jerrors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	out, _, code := runTest(t, "", "schema")
+	require.Equal(t, 0, code)
+	require.Equal(t, string(jerrors.Schema()), out)
+
+	name := filepath.Join(t.TempDir(), "jerrors.schema.json")
+	_, _, code = runTest(t, "", "schema", "-o", name)
+	require.Equal(t, 0, code)
+
+	b, err := os.ReadFile(name)
+	require.Nil(t, err)
+	require.Equal(t, jerrors.Schema(), b)
+
+	_, _, code = runTest(t, "", "schema", "-o", filepath.Join(t.TempDir(), "missing", "schema.json"))
+	require.Equal(t, 1, code)
+}
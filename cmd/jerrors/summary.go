@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chadeldridge/jerrors"
+)
+
+// summaryGroup counts the Errors with the same Level, Code (or Message) and metadata values.
+type summaryGroup struct {
+	Level    jerrors.Level     `json:"level"`
+	Code     string            `json:"code,omitempty"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Count    int               `json:"count"`
+	First    *time.Time        `json:"first_seen,omitempty"`
+	Last     *time.Time        `json:"last_seen,omitempty"`
+	Rate     float64           `json:"rate_per_minute"`
+}
+
+// summary is the report printed by the summary command.
+type summary struct {
+	Total  int             `json:"total"`
+	Levels map[string]int  `json:"levels"`
+	Groups []*summaryGroup `json:"groups"`
+	// Distinct is the number of groups before -top kept only the largest.
+	Distinct int `json:"distinct"`
+
+	keys   []string
+	groups map[string]*summaryGroup
+}
+
+func newSummary(keys []string) *summary {
+	return &summary{
+		Levels: map[string]int{},
+		Groups: []*summaryGroup{},
+		keys:   keys,
+		groups: map[string]*summaryGroup{},
+	}
+}
+
+// add counts e in its group.
+func (s *summary) add(e jerrors.Error) {
+	values := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		values[i] = e.Metadata[k]
+	}
+
+	code := e.Code()
+	id := e.Message
+	if code != "" {
+		id = "code:" + code
+	}
+	key := e.Level.String() + "\x00" + id + "\x00" + strings.Join(values, "\x00")
+
+	g, ok := s.groups[key]
+	if !ok {
+		g = &summaryGroup{Level: e.Level, Code: code, Message: e.Message}
+		if len(s.keys) > 0 {
+			g.Metadata = make(map[string]string, len(s.keys))
+			for i, k := range s.keys {
+				g.Metadata[k] = values[i]
+			}
+		}
+
+		s.groups[key] = g
+		s.Groups = append(s.Groups, g)
+	}
+
+	g.Count++
+	s.Total++
+	s.Levels[e.Level.String()]++
+
+	if e.Time != nil {
+		t := *e.Time
+		if g.First == nil || t.Before(*g.First) {
+			g.First = &t
+		}
+
+		if g.Last == nil || t.After(*g.Last) {
+			g.Last = &t
+		}
+	}
+}
+
+// finish computes the rates, sorts the groups by count and keeps the top n. n <= 0 keeps all.
+func (s *summary) finish(n int) {
+	for _, g := range s.Groups {
+		if g.First != nil {
+			minutes := max(g.Last.Sub(*g.First).Minutes(), 1)
+			g.Rate = float64(g.Count) / minutes
+		}
+	}
+
+	sort.SliceStable(s.Groups, func(i, j int) bool {
+		a, b := s.Groups[i], s.Groups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+
+		return a.Level > b.Level
+	})
+
+	s.Distinct = len(s.Groups)
+	if n > 0 && len(s.Groups) > n {
+		s.Groups = s.Groups[:n]
+	}
+}
+
+// writeText prints the summary as a table followed by the totals per level.
+func (s *summary) writeText(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"COUNT", "LEVEL", "CODE", "MESSAGE"}
+	for _, k := range s.keys {
+		header = append(header, strings.ToUpper(k))
+	}
+	header = append(header, "FIRST SEEN", "LAST SEEN", "RATE/MIN")
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, g := range s.Groups {
+		row := []string{strconv.Itoa(g.Count), g.Level.String(), dash(g.Code), g.Message}
+		for _, k := range s.keys {
+			row = append(row, dash(g.Metadata[k]))
+		}
+		row = append(row, timeCell(g.First), timeCell(g.Last), "-")
+		if g.First != nil {
+			row[len(row)-1] = strconv.FormatFloat(g.Rate, 'f', 2, 64)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+
+	var levels []string
+	for l := jerrors.FATAL; l >= 0; l-- {
+		if n := s.Levels[l.String()]; n > 0 {
+			name := l.String()
+			if name == "" {
+				name = "no level"
+			}
+			levels = append(levels, fmt.Sprintf("%d %s", n, name))
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d errors in %d groups", s.Total, s.Distinct)
+	if len(levels) > 0 {
+		fmt.Fprintf(w, ": %s", strings.Join(levels, ", "))
+	}
+	fmt.Fprintln(w)
+
+	if len(s.Groups) < s.Distinct {
+		fmt.Fprintf(w, "showing the top %d groups\n", len(s.Groups))
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func timeCell(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(prettyTimeFormat)
+}
+
+// runSummary aggregates the Errors that pass the filters and prints counts per group.
+func runSummary(ctx context.Context, args []string, e env) int {
+	fs := flag.NewFlagSet("summary", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: jerrors summary [flags] [file ...]")
+		fs.PrintDefaults()
+	}
+
+	var f filter
+	f.register(fs)
+	by := fs.String("by", "", "comma separated metadata `keys` to group by besides level and code or message")
+	top := fs.Int("top", 20, "show only the `n` largest groups, 0 shows all")
+	format := fs.String("format", "text", "output `format`: text or json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(e.stderr, "jerrors: invalid -format %q, use text or json\n", *format)
+		return 2
+	}
+
+	if err := f.compile(time.Now()); err != nil {
+		fmt.Fprintln(e.stderr, "jerrors:", err)
+		return 2
+	}
+
+	var keys []string
+	for _, k := range strings.Split(*by, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	s := newSummary(keys)
+	in := input{names: fs.Args(), stdin: e.stdin}
+	err := in.read(ctx, func(err jerrors.Error) {
+		if f.match(err) {
+			s.add(err)
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(e.stderr, "jerrors:", err)
+		return 1
+	}
+
+	s.finish(*top)
+	if *format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(s); err != nil {
+			fmt.Fprintln(e.stderr, "jerrors:", err)
+			return 1
+		}
+
+		return 0
+	}
+
+	s.writeText(e.stdout)
+	return 0
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const summaryLog = `{"time":"2024-07-18T13:00:00Z","level":"error","message":"user 1 not found","metadata":{"code":"not_found","host":"a"}}
+{"time":"2024-07-18T13:04:00Z","level":"error","message":"user 2 not found","metadata":{"code":"not_found","host":"a"}}
+{"time":"2024-07-18T13:01:00Z","level":"error","message":"user 3 not found","metadata":{"code":"not_found","host":"b"}}
+{"time":"2024-07-18T13:02:00Z","level":"warn","message":"slow query","metadata":{"host":"b"}}
+{"time":"2024-07-18T13:02:30Z","level":"warn","message":"slow query","metadata":{"host":"b"}}
+{"level":"info","message":"no time"}
+`
+
+func TestSummaryText(t *testing.T) {
+	out, _, code := runTest(t, summaryLog, "summary")
+	require.Equal(t, 0, code)
+	require.Equal(t, `COUNT  LEVEL  CODE       MESSAGE           FIRST SEEN               LAST SEEN                RATE/MIN
+3      error  not_found  user 1 not found  2024-07-18 13:00:00.000  2024-07-18 13:04:00.000  0.75
+2      warn   -          slow query        2024-07-18 13:02:00.000  2024-07-18 13:02:30.000  2.00
+1      info   -          no time           -                        -                        -
+
+6 errors in 3 groups: 3 error, 2 warn, 1 info
+`, out)
+}
+
+func TestSummaryByTop(t *testing.T) {
+	out, _, code := runTest(t, summaryLog, "summary", "-by", "host", "-top", "2", "-level", "warn")
+	require.Equal(t, 0, code)
+	require.Equal(t, `COUNT  LEVEL  CODE       MESSAGE           HOST  FIRST SEEN               LAST SEEN                RATE/MIN
+2      error  not_found  user 1 not found  a     2024-07-18 13:00:00.000  2024-07-18 13:04:00.000  0.50
+2      warn   -          slow query        b     2024-07-18 13:02:00.000  2024-07-18 13:02:30.000  2.00
+
+5 errors in 3 groups: 3 error, 2 warn
+showing the top 2 groups
+`, out)
+}
+
+func TestSummaryJSON(t *testing.T) {
+	out, _, code := runTest(t, summaryLog, "summary", "-format", "json", "-by", "host", "-top", "0")
+	require.Equal(t, 0, code)
+
+	var s struct {
+		Total    int            `json:"total"`
+		Levels   map[string]int `json:"levels"`
+		Distinct int            `json:"distinct"`
+		Groups   []struct {
+			Level    string            `json:"level"`
+			Code     string            `json:"code"`
+			Message  string            `json:"message"`
+			Metadata map[string]string `json:"metadata"`
+			Count    int               `json:"count"`
+			First    string            `json:"first_seen"`
+			Last     string            `json:"last_seen"`
+			Rate     float64           `json:"rate_per_minute"`
+		} `json:"groups"`
+	}
+	require.Nil(t, json.Unmarshal([]byte(out), &s))
+
+	require.Equal(t, 6, s.Total)
+	require.Equal(t, map[string]int{"error": 3, "warn": 2, "info": 1}, s.Levels)
+	require.Equal(t, 4, s.Distinct)
+	require.Len(t, s.Groups, 4)
+
+	g := s.Groups[0]
+	require.Equal(t, "error", g.Level)
+	require.Equal(t, "not_found", g.Code)
+	require.Equal(t, map[string]string{"host": "a"}, g.Metadata)
+	require.Equal(t, 2, g.Count)
+	require.Equal(t, "2024-07-18T13:00:00Z", g.First)
+	require.Equal(t, "2024-07-18T13:04:00Z", g.Last)
+	require.Equal(t, 0.5, g.Rate)
+
+	require.Equal(t, "", s.Groups[3].First)
+}
+
+func TestSummaryEmptyAndBadFlags(t *testing.T) {
+	out, _, code := runTest(t, "", "summary", "-format", "json")
+	require.Equal(t, 0, code)
+	require.JSONEq(t, `{"total":0,"levels":{},"groups":[],"distinct":0}`, out)
+
+	_, _, code = runTest(t, "", "summary", "-format", "xml")
+	require.Equal(t, 2, code)
+
+	_, _, code = runTest(t, "", "summary", "-level", "loud")
+	require.Equal(t, 2, code)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chadeldridge/jerrors"
+)
+
+// runView prints the Errors that pass the filters in the chosen format.
+func runView(ctx context.Context, args []string, e env) int {
+	fs := flag.NewFlagSet("view", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: jerrors view [flags] [file ...]")
+		fs.PrintDefaults()
+	}
+
+	var f filter
+	f.register(fs)
+	format := fs.String("format", "pretty", "output `format`: "+formatNames())
+	follow := fs.Bool("f", false, "follow the files as they grow, like tail -f")
+	poll := fs.Duration("poll", 250*time.Millisecond, "how often to check followed files for new lines")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	render, ok := formats[*format]
+	if !ok {
+		fmt.Fprintf(e.stderr, "jerrors: invalid -format %q, use %s\n", *format, formatNames())
+		return 2
+	}
+
+	if err := f.compile(time.Now()); err != nil {
+		fmt.Fprintln(e.stderr, "jerrors:", err)
+		return 2
+	}
+
+	var mu sync.Mutex
+	in := input{names: fs.Args(), stdin: e.stdin, follow: *follow, poll: *poll}
+	err := in.read(ctx, func(err jerrors.Error) {
+		if !f.match(err) {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintln(e.stdout, render(err))
+	})
+	if err != nil {
+		fmt.Fprintln(e.stderr, "jerrors:", err)
+		return 1
+	}
+
+	return 0
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
@@ -1,28 +1,62 @@
 package jerrors
 
-var config Config
+import (
+	"slices"
+	"sync/atomic"
+)
 
-func init() {
-	SetConfig(DefaultConfig())
+// config is the active Config. SetConfig replaces it as a whole, so it may be read while another
+// goroutine calls SetConfig. Load it once for each use that reads several fields. It is set when
+// declared so package level Errors can be created before init runs.
+var config = newConfigPointer(DefaultConfig())
+
+func newConfigPointer(c Config) *atomic.Pointer[Config] {
+	p := new(atomic.Pointer[Config])
+	p.Store(&c)
+	return p
 }
 
 type Config struct {
 	// Record the Level
-	LogLevel bool
+	LogLevel bool `json:"log_level"`
 	// Record the timestamp
-	LogTime bool
+	LogTime bool `json:"log_time"`
 	// Minimum Level to log
-	LoggingLevel Level
+	LoggingLevel Level `json:"level"`
 	// Record the caller details
-	LogCaller bool
+	LogCaller bool `json:"log_caller"`
 	// CallerDepth is how many function calls to step back before getting Caller information.
 	// This should be enough to get us back to the initiating function.
-	CallerDepth int
+	CallerDepth int `json:"caller_depth"`
 	// CallersToShow sets how many calling functions to show.
-	CallersToShow int
+	CallersToShow int `json:"callers_to_show"`
+	// TimeFormat is how Time is written: rfc3339, rfc3339nano, unix, unixmilli, unixnano, in any
+	// case, or a custom time.Format layout. Defaults to rfc3339nano.
+	TimeFormat string `json:"time_format"`
+	// UTC records and writes Time in UTC instead of local time.
+	UTC bool `json:"utc"`
+	// LogFingerprint adds each Error's Fingerprint to its JSON as "fingerprint".
+	LogFingerprint bool `json:"log_fingerprint"`
+	// FingerprintKeys are the Metadata keys included in an Error's Fingerprint.
+	FingerprintKeys []string `json:"fingerprint_keys"`
+	// FingerprintCaller includes the function that created the Error in its Fingerprint. Requires
+	// LogCaller.
+	FingerprintCaller bool `json:"fingerprint_caller"`
+	// LogSchemaVersion adds SchemaVersion to each Error's JSON as "schema_version".
+	LogSchemaVersion bool `json:"log_schema_version"`
+	// DuplicateKeys is what happens when a Metadata key is added again: DuplicateLastWins,
+	// DuplicateKeepBoth or DuplicateError. Defaults to DuplicateLastWins.
+	DuplicateKeys string `json:"duplicate_keys"`
+}
+
+// GetConfig returns the current Config. LoggingLevel reflects any changes made with SetLogLevel.
+func GetConfig() Config {
+	c := *config.Load()
+	c.LoggingLevel = loggingLevel.Level()
+	c.FingerprintKeys = slices.Clone(c.FingerprintKeys)
+	return c
 }
 
-func GetConfig() Config { return config }
 func NewConfig() Config { return DefaultConfig() }
 
 func DefaultConfig() Config {
@@ -33,13 +67,54 @@ func DefaultConfig() Config {
 		LogCaller:     false,
 		CallerDepth:   2,
 		CallersToShow: 2,
+		TimeFormat:    TimeRFC3339Nano,
+		UTC:           false,
+		DuplicateKeys: DuplicateLastWins,
 	}
 }
 
+// SetConfig replaces the active Config. It is safe to call while other goroutines create and log
+// Errors, which each see either the old or the new Config.
 func SetConfig(newConfig Config) {
 	if newConfig.LoggingLevel == 0 {
 		newConfig.LoggingLevel = INFO
 	}
+	newConfig.FingerprintKeys = slices.Clone(newConfig.FingerprintKeys)
+	newConfig.TimeFormat, _ = timeFormatName(newConfig.TimeFormat)
+
+	config.Store(&newConfig)
+	loggingLevel.SetLevel(newConfig.LoggingLevel)
+}
+
+// Validate checks the Config for bad values. Returns nil or an *Errors with a Field Error for each
+// bad value. Field paths use the Config's JSON keys.
+func (c Config) Validate() error {
+	errs := New()
+	if c.LoggingLevel < DEBUG || c.LoggingLevel > FATAL {
+		errs.Field("level", ERROR, "unknown level", "value", int(c.LoggingLevel))
+	}
+
+	if c.CallerDepth < 0 {
+		errs.Field("caller_depth", ERROR, "must not be negative", "value", c.CallerDepth)
+	}
+
+	if c.CallersToShow < 0 {
+		errs.Field("callers_to_show", ERROR, "must not be negative", "value", c.CallersToShow)
+	}
+
+	if _, ok := timeFormatName(c.TimeFormat); !ok && c.TimeFormat != "" && !validTimeLayout(c.TimeFormat) {
+		errs.Field("time_format", ERROR, "unknown time format or invalid layout", "value", c.TimeFormat)
+	}
+
+	switch c.DuplicateKeys {
+	case "", DuplicateLastWins, DuplicateKeepBoth, DuplicateError:
+	default:
+		errs.Field("duplicate_keys", ERROR, "unknown duplicate key policy", "value", c.DuplicateKeys)
+	}
+
+	if errs.IsEmpty() {
+		return nil
+	}
 
-	config = newConfig
+	return &errs
 }
@@ -0,0 +1,281 @@
+package jerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvPrefix is used by ConfigFromEnv when no prefix is given.
+const DefaultEnvPrefix = "JERRORS"
+
+// configOption describes how a Config field is set from the environment and flags.
+type configOption struct {
+	// key is the Config's JSON key and is used as the Field of validation errors.
+	key    string
+	env    string
+	flag   string
+	usage  string
+	isBool bool
+	set    func(c *Config, v string) error
+}
+
+var configOptions = []configOption{
+	{
+		key: "log_level", env: "LOG_LEVEL", flag: "jerrors-log-level", isBool: true,
+		usage: "record the level of each error",
+		set:   func(c *Config, v string) error { return parseBool(v, &c.LogLevel) },
+	},
+	{
+		key: "log_time", env: "LOG_TIME", flag: "jerrors-log-time", isBool: true,
+		usage: "record the time of each error",
+		set:   func(c *Config, v string) error { return parseBool(v, &c.LogTime) },
+	},
+	{
+		key: "level", env: "LEVEL", flag: "jerrors-level",
+		usage: "minimum level to log: debug, info, warn, error or fatal",
+		set:   func(c *Config, v string) error { return parseLevel(v, &c.LoggingLevel) },
+	},
+	{
+		key: "log_caller", env: "LOG_CALLER", flag: "jerrors-log-caller", isBool: true,
+		usage: "record the calling functions of each error",
+		set:   func(c *Config, v string) error { return parseBool(v, &c.LogCaller) },
+	},
+	{
+		key: "caller_depth", env: "CALLER_DEPTH", flag: "jerrors-caller-depth",
+		usage: "function calls to step back before getting caller information",
+		set:   func(c *Config, v string) error { return parseInt(v, &c.CallerDepth) },
+	},
+	{
+		key: "callers_to_show", env: "CALLERS_TO_SHOW", flag: "jerrors-callers-to-show",
+		usage: "number of calling functions to show",
+		set:   func(c *Config, v string) error { return parseInt(v, &c.CallersToShow) },
+	},
+	{
+		key: "time_format", env: "TIME_FORMAT", flag: "jerrors-time-format",
+		usage: "time format: rfc3339, rfc3339nano, unix, unixmilli, unixnano or a time.Format layout",
+		set:   func(c *Config, v string) error { c.TimeFormat, _ = timeFormatName(v); return nil },
+	},
+	{
+		key: "utc", env: "UTC", flag: "jerrors-utc", isBool: true,
+		usage: "record times in UTC",
+		set:   func(c *Config, v string) error { return parseBool(v, &c.UTC) },
+	},
+	{
+		key: "log_fingerprint", env: "LOG_FINGERPRINT", flag: "jerrors-log-fingerprint", isBool: true,
+		usage: "add a fingerprint to each error for grouping",
+		set:   func(c *Config, v string) error { return parseBool(v, &c.LogFingerprint) },
+	},
+	{
+		key: "fingerprint_keys", env: "FINGERPRINT_KEYS", flag: "jerrors-fingerprint-keys",
+		usage: "comma separated metadata keys included in the fingerprint",
+		set:   func(c *Config, v string) error { c.FingerprintKeys = splitList(v); return nil },
+	},
+	{
+		key: "fingerprint_caller", env: "FINGERPRINT_CALLER", flag: "jerrors-fingerprint-caller", isBool: true,
+		usage: "include the calling function in the fingerprint",
+		set:   func(c *Config, v string) error { return parseBool(v, &c.FingerprintCaller) },
+	},
+	{
+		key: "log_schema_version", env: "LOG_SCHEMA_VERSION", flag: "jerrors-log-schema-version", isBool: true,
+		usage: "add the schema version to each error",
+		set:   func(c *Config, v string) error { return parseBool(v, &c.LogSchemaVersion) },
+	},
+	{
+		key: "duplicate_keys", env: "DUPLICATE_KEYS", flag: "jerrors-duplicate-keys",
+		usage: "when a metadata key is added again: last, both or error",
+		set:   func(c *Config, v string) error { c.DuplicateKeys = v; return nil },
+	},
+}
+
+// ConfigFromEnv returns DefaultConfig overridden by any of these environment variables, using
+// DefaultEnvPrefix if prefix is "":
+//
+//	JERRORS_LEVEL=debug
+//	JERRORS_LOG_LEVEL=true
+//	JERRORS_LOG_TIME=true
+//	JERRORS_LOG_CALLER=true
+//	JERRORS_CALLER_DEPTH=2
+//	JERRORS_CALLERS_TO_SHOW=2
+//	JERRORS_TIME_FORMAT=rfc3339
+//	JERRORS_UTC=true
+//	JERRORS_LOG_FINGERPRINT=true
+//	JERRORS_FINGERPRINT_KEYS=service,table
+//	JERRORS_FINGERPRINT_CALLER=true
+//	JERRORS_LOG_SCHEMA_VERSION=true
+//	JERRORS_DUPLICATE_KEYS=last
+//
+// Bad values are returned as an *Errors.
+func ConfigFromEnv(prefix string) (Config, error) {
+	c := DefaultConfig()
+	errs := New()
+	applyEnv(&c, prefix, &errs)
+	return c, validateConfig(c, &errs)
+}
+
+// ConfigFromFile returns DefaultConfig overridden by the JSON file at path. Keys match the Config
+// JSON tags, e.g. {"level":"debug","log_caller":true}. Keys missing from the file keep their
+// default. Bad values are returned as an *Errors.
+func ConfigFromFile(path string) (Config, error) {
+	c := DefaultConfig()
+	errs := New()
+	if err := applyFile(&c, path, &errs); err != nil {
+		return c, err
+	}
+
+	return c, validateConfig(c, &errs)
+}
+
+// LoadConfig builds a Config with the precedence defaults < file < environment < flags. Skips
+// the file if path is "" and the flags if flags is nil. All bad values are returned together as
+// an *Errors.
+func LoadConfig(path, prefix string, flags *ConfigFlags) (Config, error) {
+	c := DefaultConfig()
+	errs := New()
+	if path != "" {
+		if err := applyFile(&c, path, &errs); err != nil {
+			return c, err
+		}
+	}
+
+	applyEnv(&c, prefix, &errs)
+	if flags != nil {
+		flags.apply(&c, &errs)
+	}
+
+	return c, validateConfig(c, &errs)
+}
+
+// ConfigFlags holds the values of the flags added by RegisterFlags.
+type ConfigFlags struct {
+	values map[string]string
+}
+
+// RegisterFlags adds a -jerrors-* flag for each Config option to fs. Only flags given on the
+// command line override the Config in Apply.
+// Example:
+// flags := jerrors.RegisterFlags(flag.CommandLine)
+// flag.Parse()
+// c, err := flags.Apply(jerrors.DefaultConfig())
+func RegisterFlags(fs *flag.FlagSet) *ConfigFlags {
+	f := &ConfigFlags{values: make(map[string]string)}
+	for _, opt := range configOptions {
+		set := func(v string) error {
+			f.values[opt.key] = v
+			return nil
+		}
+
+		if opt.isBool {
+			fs.BoolFunc(opt.flag, opt.usage, set)
+			continue
+		}
+		fs.Func(opt.flag, opt.usage, set)
+	}
+
+	return f
+}
+
+// Apply returns c overridden by the flags that were set. Bad values are returned as an *Errors.
+func (f *ConfigFlags) Apply(c Config) (Config, error) {
+	errs := New()
+	f.apply(&c, &errs)
+	return c, validateConfig(c, &errs)
+}
+
+func (f *ConfigFlags) apply(c *Config, errs *Errors) {
+	for _, opt := range configOptions {
+		if v, ok := f.values[opt.key]; ok {
+			setOption(c, opt, v, "-"+opt.flag, errs)
+		}
+	}
+}
+
+func applyEnv(c *Config, prefix string, errs *Errors) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+
+	for _, opt := range configOptions {
+		name := prefix + "_" + opt.env
+		if v, ok := os.LookupEnv(name); ok {
+			setOption(c, opt, v, name, errs)
+		}
+	}
+}
+
+// applyFile reads path into c. Read errors are returned, parse errors are added to errs.
+func applyFile(c *Config, path string, errs *Errors) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(c); err != nil {
+		errs.NewError(ERROR, "invalid config file", "path", path, "error", err.Error())
+	}
+
+	return nil
+}
+
+func setOption(c *Config, opt configOption, v, source string, errs *Errors) {
+	if err := opt.set(c, v); err != nil {
+		errs.Field(opt.key, ERROR, "invalid value", "value", v, "source", source)
+	}
+}
+
+// validateConfig adds the Validate errors for c to errs and returns errs if it is not empty.
+func validateConfig(c Config, errs *Errors) error {
+	if err := c.Validate(); err != nil {
+		errs.Append(*err.(*Errors))
+	}
+
+	if errs.IsEmpty() {
+		return nil
+	}
+
+	return errs
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// parseBool, parseInt and parseLevel only set dst if v is valid.
+func parseBool(v string, dst *bool) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err == nil {
+		*dst = b
+	}
+	return err
+}
+
+func parseInt(v string, dst *int) error {
+	i, err := strconv.Atoi(strings.TrimSpace(v))
+	if err == nil {
+		*dst = i
+	}
+	return err
+}
+
+func parseLevel(v string, dst *Level) error {
+	l := GetLevel(strings.TrimSpace(v))
+	if l == 0 {
+		return strconv.ErrSyntax
+	}
+
+	*dst = l
+	return nil
+}
@@ -0,0 +1,154 @@
+package jerrors
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, j string) string {
+	path := filepath.Join(t.TempDir(), "jerrors.json")
+	require.Nil(t, os.WriteFile(path, []byte(j), 0o644))
+	return path
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.Nil(t, DefaultConfig().Validate())
+
+	c := DefaultConfig()
+	c.LoggingLevel = 0
+	c.CallerDepth = -1
+	c.CallersToShow = -2
+	err := c.Validate()
+	require.Error(t, err)
+
+	errs, ok := err.(*Errors)
+	require.True(t, ok)
+	require.Equal(t, 3, len(errs.Errors))
+	require.Equal(t, "level", errs.Errors[0].Field.String())
+	require.Equal(t, "caller_depth", errs.Errors[1].Field.String())
+	require.Equal(t, "callers_to_show", errs.Errors[2].Field.String())
+
+	for _, f := range []string{"RFC3339", "UnixMilli", "2006-01-02 15:04:05", "Jan _2 15:04"} {
+		c := DefaultConfig()
+		c.TimeFormat = f
+		require.Nil(t, c.Validate(), f)
+	}
+
+	for _, f := range []string{"iso", "002 Jan 2"} {
+		c := DefaultConfig()
+		c.TimeFormat = f
+		require.Error(t, c.Validate(), f)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("JERRORS_LEVEL", "debug")
+	t.Setenv("JERRORS_LOG_CALLER", "true")
+	t.Setenv("JERRORS_CALLER_DEPTH", "3")
+	t.Setenv("JERRORS_TIME_FORMAT", "RFC3339")
+
+	c, err := ConfigFromEnv("")
+	require.Nil(t, err)
+	require.Equal(t, TimeRFC3339, c.TimeFormat)
+	require.Equal(t, DEBUG, c.LoggingLevel)
+	require.True(t, c.LogCaller)
+	require.Equal(t, 3, c.CallerDepth)
+	require.True(t, c.LogTime)
+
+	// Custom prefix.
+	t.Setenv("APP_LOG_TIME", "false")
+	c, err = ConfigFromEnv("APP")
+	require.Nil(t, err)
+	require.False(t, c.LogTime)
+	require.Equal(t, INFO, c.LoggingLevel)
+}
+
+func TestConfigFromEnvInvalid(t *testing.T) {
+	t.Setenv("JERRORS_LEVEL", "loud")
+	t.Setenv("JERRORS_LOG_TIME", "maybe")
+	t.Setenv("JERRORS_CALLER_DEPTH", "-1")
+	t.Setenv("JERRORS_TIME_FORMAT", "iso")
+
+	c, err := ConfigFromEnv("")
+	require.Error(t, err)
+	require.Equal(t, INFO, c.LoggingLevel)
+
+	errs := err.(*Errors)
+	require.Equal(t, 4, len(errs.Errors))
+	m := errs.ByField()
+	require.Equal(t, "JERRORS_LEVEL", m["/level"][0].Metadata["source"])
+	require.Equal(t, "maybe", m["/log_time"][0].Metadata["value"])
+	require.Equal(t, "must not be negative", m["/caller_depth"][0].Message)
+	require.Equal(t, "iso", m["/time_format"][0].Metadata["value"])
+}
+
+func TestConfigFromFile(t *testing.T) {
+	path := writeConfigFile(t, `{"level":"warn","log_caller":true,"callers_to_show":4}`)
+
+	c, err := ConfigFromFile(path)
+	require.Nil(t, err)
+	require.Equal(t, WARN, c.LoggingLevel)
+	require.True(t, c.LogCaller)
+	require.Equal(t, 4, c.CallersToShow)
+	require.Equal(t, 2, c.CallerDepth)
+
+	// Unknown keys and levels.
+	path = writeConfigFile(t, `{"level":"loud"}`)
+	_, err = ConfigFromFile(path)
+	require.Error(t, err)
+	require.Equal(t, "unknown level", err.(*Errors).First().Message)
+
+	path = writeConfigFile(t, `{"colour":true}`)
+	_, err = ConfigFromFile(path)
+	require.Error(t, err)
+	require.Equal(t, "invalid config file", err.(*Errors).First().Message)
+
+	// Read errors are not Errors.
+	_, err = ConfigFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestConfigRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	require.Nil(t, fs.Parse([]string{"-jerrors-level", "error", "-jerrors-log-caller"}))
+
+	c, err := flags.Apply(DefaultConfig())
+	require.Nil(t, err)
+	require.Equal(t, ERROR, c.LoggingLevel)
+	require.True(t, c.LogCaller)
+	require.True(t, c.LogTime)
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	flags = RegisterFlags(fs)
+	require.Nil(t, fs.Parse([]string{"-jerrors-callers-to-show=x"}))
+	_, err = flags.Apply(DefaultConfig())
+	require.Error(t, err)
+	require.Equal(t, "-jerrors-callers-to-show", err.(*Errors).First().Metadata["source"])
+}
+
+func TestConfigLoadConfig(t *testing.T) {
+	path := writeConfigFile(t, `{"level":"warn","log_caller":true,"caller_depth":5}`)
+	t.Setenv("JERRORS_LEVEL", "error")
+	t.Setenv("JERRORS_CALLER_DEPTH", "4")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	require.Nil(t, fs.Parse([]string{"-jerrors-level=debug"}))
+
+	c, err := LoadConfig(path, "", flags)
+	require.Nil(t, err)
+	// flags > env > file > defaults
+	require.Equal(t, DEBUG, c.LoggingLevel)
+	require.Equal(t, 4, c.CallerDepth)
+	require.True(t, c.LogCaller)
+	require.True(t, c.LogTime)
+
+	c, err = LoadConfig("", "", nil)
+	require.Nil(t, err)
+	require.Equal(t, ERROR, c.LoggingLevel)
+}
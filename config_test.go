@@ -1,7 +1,10 @@
 package jerrors
 
 import (
+	"io"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -14,6 +17,9 @@ func TestDefaultConfig(t *testing.T) {
 		LogCaller:     false,
 		CallerDepth:   2,
 		CallersToShow: 2,
+		TimeFormat:    TimeRFC3339Nano,
+		UTC:           false,
+		DuplicateKeys: DuplicateLastWins,
 	}
 
 	got := NewConfig()
@@ -46,4 +52,44 @@ func TestSetConfig(t *testing.T) {
 	require.Equal(t, want.LogLevel, got.LogLevel)
 	require.Equal(t, want.LogTime, got.LogTime)
 	require.Equal(t, INFO, got.LoggingLevel)
+
+	// Time format names match ignoring case.
+	want.TimeFormat = "RFC3339"
+	SetConfig(want)
+	defer SetConfig(DefaultConfig())
+	require.Equal(t, TimeRFC3339, GetConfig().TimeFormat)
+	tm := time.Date(2024, 7, 18, 13, 9, 25, 0, time.UTC)
+	require.Equal(t, `{"time":"2024-07-18T13:09:25Z"}`, string(Error{Time: &tm}.AppendJSON(nil)))
+}
+
+func TestSetConfigConcurrent(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	SetLogOutput(io.Discard)
+	defer SetLogOutput(nil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c := DefaultConfig()
+			c.LogCaller = i%2 == 0
+			c.FingerprintKeys = []string{"user"}
+			SetConfig(c)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		e := NewError(ERROR, testMessage, "user", "test1")
+		e.Log()
+		_ = e.Fingerprint()
+	}
+	wg.Wait()
+
+	keys := []string{"user"}
+	c := DefaultConfig()
+	c.FingerprintKeys = keys
+	SetConfig(c)
+	keys[0] = "changed"
+	require.Equal(t, []string{"user"}, GetConfig().FingerprintKeys)
 }
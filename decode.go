@@ -0,0 +1,99 @@
+package jerrors
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ErrNoErrors is returned by ParseLine for lines that do not hold any Errors.
+var ErrNoErrors = errors.New("jerrors: line holds no errors")
+
+// ParseLine decodes a line written by Log or Fatal. The line may hold an Error, a JSON array of
+// Errors as written by Errors.Error, or an Errors object whose groups are flattened. Text before
+// the JSON, such as a log prefix or the message passed to Errors.Fatal, is skipped. Returns
+// ErrNoErrors if the line holds none.
+func ParseLine(line []byte) ([]Error, error) {
+	i := bytes.IndexAny(line, "{[")
+	if i < 0 {
+		return nil, ErrNoErrors
+	}
+	line = bytes.TrimSpace(line[i:])
+
+	if line[0] == '[' {
+		var errs []Error
+		if err := json.Unmarshal(line, &errs); err != nil {
+			return nil, err
+		}
+
+		if len(errs) == 0 {
+			return nil, ErrNoErrors
+		}
+
+		return errs, nil
+	}
+
+	var probe struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(line, &probe); err != nil {
+		return nil, err
+	}
+
+	if probe.Errors != nil {
+		var errs Errors
+		if err := json.Unmarshal(line, &errs); err != nil {
+			return nil, err
+		}
+
+		if errs.IsEmpty() {
+			return nil, ErrNoErrors
+		}
+
+		return errs.Flatten(), nil
+	}
+
+	var e Error
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, err
+	}
+
+	if e.Message == "" && e.Level == 0 {
+		return nil, ErrNoErrors
+	}
+
+	return []Error{e}, nil
+}
+
+// Decoder reads Errors from a log written by Log, one line at a time.
+// Example:
+// d := jerrors.NewDecoder(os.Stdin)
+// for errs, err := d.Decode(); err == nil; errs, err = d.Decode() { ... }
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the Errors on the next line holding any, see ParseLine. Lines without Errors,
+// including lines that are not valid JSON such as panics, are skipped. Returns io.EOF at the end
+// of the log or the error reading it.
+func (d *Decoder) Decode() ([]Error, error) {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if errs, perr := ParseLine(line); perr == nil {
+				return errs, nil
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package jerrors
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	errs, err := ParseLine([]byte(`{"level":"error","message":"disk full","metadata":{"mount":"/var"}}`))
+	require.Nil(t, err)
+	require.Len(t, errs, 1)
+	require.Equal(t, ERROR, errs[0].Level)
+	require.Equal(t, "/var", errs[0].Metadata["mount"])
+
+	errs, err = ParseLine([]byte(`[{"level":"warn","message":"a"},{"level":"error","message":"b"}]`))
+	require.Nil(t, err)
+	require.Len(t, errs, 2)
+	require.Equal(t, "b", errs[1].Message)
+
+	errs, err = ParseLine([]byte(`{"errors":[{"level":"info","message":"a"}],"level":"error","groups":[{"name":"db","errors":[{"level":"error","message":"b"}],"level":"error"}]}`))
+	require.Nil(t, err)
+	require.Len(t, errs, 2)
+	require.Equal(t, "db", errs[1].Metadata[GroupKey])
+
+	// Text before the JSON is skipped.
+	errs, err = ParseLine([]byte(`2024/07/18 13:00:00 failed: {"level":"fatal","message":"cannot start"}`))
+	require.Nil(t, err)
+	require.Equal(t, FATAL, errs[0].Level)
+
+	for _, line := range []string{"plain text", `{"other":1}`, `[]`, `{"errors":[],"level":""}`} {
+		_, err = ParseLine([]byte(line))
+		require.True(t, errors.Is(err, ErrNoErrors), line)
+	}
+
+	_, err = ParseLine([]byte(`{"level":`))
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrNoErrors))
+}
+
+func TestDecoder(t *testing.T) {
+	log := `{"level":"info","message":"a"}
+starting up
+
+{"level":"broken
+[{"level":"warn","message":"b"},{"level":"error","message":"c"}]
+{"level":"error","message":"d"}`
+
+	d := NewDecoder(strings.NewReader(log))
+
+	errs, err := d.Decode()
+	require.Nil(t, err)
+	require.Equal(t, "a", errs[0].Message)
+
+	// The broken line is skipped.
+	errs, err = d.Decode()
+	require.Nil(t, err)
+	require.Len(t, errs, 2)
+
+	// The last line has no line ending.
+	errs, err = d.Decode()
+	require.Nil(t, err)
+	require.Equal(t, "d", errs[0].Message)
+
+	_, err = d.Decode()
+	require.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,301 @@
+package jerrors
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+// CompareOption changes how Errors are compared by Errors.Equal and Diff.
+type CompareOption func(*compareOptions)
+
+type compareOptions struct {
+	unordered      bool
+	ignoreMetadata map[string]bool
+}
+
+// Unordered compares Errors lists without regard to the order of their Errors.
+func Unordered() CompareOption {
+	return func(o *compareOptions) { o.unordered = true }
+}
+
+// IgnoreMetadata ignores the given Metadata keys when comparing, such as "caller".
+func IgnoreMetadata(keys ...string) CompareOption {
+	return func(o *compareOptions) {
+		for _, k := range keys {
+			o.ignoreMetadata[k] = true
+		}
+	}
+}
+
+func newCompareOptions(opts []CompareOption) *compareOptions {
+	o := &compareOptions{ignoreMetadata: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// metadata returns md without the ignored keys.
+func (o *compareOptions) metadata(md map[string]string) map[string]string {
+	if len(o.ignoreMetadata) == 0 {
+		return md
+	}
+
+	m := maps.Clone(md)
+	maps.DeleteFunc(m, func(k, _ string) bool { return o.ignoreMetadata[k] })
+	return m
+}
+
+func (o *compareOptions) equal(a, b Error) bool {
+	a, b = a.Resolve(), b.Resolve()
+	a.Metadata = o.metadata(a.Metadata)
+	b.Metadata = o.metadata(b.Metadata)
+	return a.Equal(b)
+}
+
+// Equal returns true if both lists hold equal Errors and groups with the same names. Errors are
+// compared with Error.Equal, so Time is ignored. Errors must be in the same order unless Unordered
+// is given. Groups must always be in the same order.
+func (e *Errors) Equal(other Errors, opts ...CompareOption) bool {
+	if len(e.Errors) != len(other.Errors) || len(e.Groups) != len(other.Groups) {
+		return false
+	}
+
+	for i, g := range e.Groups {
+		if g.Name != other.Groups[i].Name || !g.Equal(*other.Groups[i], opts...) {
+			return false
+		}
+	}
+
+	o := newCompareOptions(opts)
+	if !o.unordered {
+		for i := range e.Errors {
+			if !o.equal(e.Errors[i], other.Errors[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	used := make([]bool, len(other.Errors))
+	for _, a := range e.Errors {
+		found := false
+		for j, b := range other.Errors {
+			if !used[j] && o.equal(a, b) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ErrorChange is an Error found in both lists with a different Level or Metadata.
+type ErrorChange struct {
+	Old Error `json:"old"`
+	New Error `json:"new"`
+	// Fields lists what changed: "level", "message", "position", "retryable", "temporary",
+	// "retry_after" and "metadata.<key>" for each changed key.
+	Fields []string `json:"fields"`
+}
+
+// ErrorsDiff holds the differences between two Errors lists.
+type ErrorsDiff struct {
+	Added   []Error       `json:"added,omitempty"`
+	Removed []Error       `json:"removed,omitempty"`
+	Changed []ErrorChange `json:"changed,omitempty"`
+}
+
+// Diff compares a to b. Errors in both lists are matched regardless of order. Errors left over
+// with the same Code (or Message if there is no Code) and Field are reported as changed, the rest
+// as removed from a or added in b. The Errors in groups are compared too, as returned by Flatten,
+// so an Error moved to another group is changed in Metadata[GroupKey].
+func Diff(a, b Errors, opts ...CompareOption) ErrorsDiff {
+	o := newCompareOptions(opts)
+	var d ErrorsDiff
+	listA, listB := a.Flatten(), b.Flatten()
+
+	// Drop the Errors found in both lists.
+	usedA := make([]bool, len(listA))
+	usedB := make([]bool, len(listB))
+	for i, ea := range listA {
+		for j, eb := range listB {
+			if !usedB[j] && o.equal(ea, eb) {
+				usedA[i], usedB[j] = true, true
+				break
+			}
+		}
+	}
+
+	// Pair what is left by identity.
+	for i, ea := range listA {
+		if usedA[i] {
+			continue
+		}
+
+		for j, eb := range listB {
+			if usedB[j] || diffKey(ea) != diffKey(eb) {
+				continue
+			}
+
+			usedA[i], usedB[j] = true, true
+			d.Changed = append(d.Changed, ErrorChange{Old: ea, New: eb, Fields: o.changedFields(ea, eb)})
+			break
+		}
+	}
+
+	for i, ea := range listA {
+		if !usedA[i] {
+			d.Removed = append(d.Removed, ea)
+		}
+	}
+
+	for j, eb := range listB {
+		if !usedB[j] {
+			d.Added = append(d.Added, eb)
+		}
+	}
+
+	return d
+}
+
+// diffKey identifies an Error across versions of a list.
+func diffKey(e Error) string {
+	id := e.Code()
+	if id == "" {
+		id = e.Message
+	}
+
+	return id + "\x00" + e.Field.Pointer()
+}
+
+func (o *compareOptions) changedFields(a, b Error) []string {
+	a, b = a.Resolve(), b.Resolve()
+	var fields []string
+	if a.Level != b.Level {
+		fields = append(fields, "level")
+	}
+
+	if a.Message != b.Message {
+		fields = append(fields, "message")
+	}
+
+	if !a.Position.Equal(b.Position) {
+		fields = append(fields, "position")
+	}
+
+	if a.Retryable != b.Retryable {
+		fields = append(fields, "retryable")
+	}
+
+	if a.Temporary != b.Temporary {
+		fields = append(fields, "temporary")
+	}
+
+	if a.RetryAfter != b.RetryAfter {
+		fields = append(fields, "retry_after")
+	}
+
+	am, bm := o.metadata(a.Metadata), o.metadata(b.Metadata)
+	keys := make(map[string]bool)
+	for k := range am {
+		keys[k] = true
+	}
+	for k := range bm {
+		keys[k] = true
+	}
+
+	var changed []string
+	for k := range keys {
+		av, aok := am[k]
+		bv, bok := bm[k]
+		if av != bv || aok != bok {
+			changed = append(changed, "metadata."+k)
+		}
+	}
+	sort.Strings(changed)
+
+	return append(fields, changed...)
+}
+
+// IsEmpty returns true if there are no differences.
+func (d ErrorsDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String renders the diff as text, one line per difference:
+//
+//	- {"level":"error","message":"removed error"}
+//	+ {"level":"warn","message":"added error"}
+//	~ "changed error": level error -> warn, metadata.user "a" -> "b"
+func (d ErrorsDiff) String() string {
+	var b strings.Builder
+	for _, e := range d.Removed {
+		b.WriteString("- " + e.String() + "\n")
+	}
+
+	for _, e := range d.Added {
+		b.WriteString("+ " + e.String() + "\n")
+	}
+
+	for _, c := range d.Changed {
+		name := fmt.Sprintf("%q", c.Old.Message)
+		if len(c.Old.Field) > 0 {
+			name += " at " + c.Old.Field.Pointer()
+		}
+
+		changes := make([]string, 0, len(c.Fields))
+		for _, f := range c.Fields {
+			switch {
+			case f == "level":
+				changes = append(changes, fmt.Sprintf("level %s -> %s", c.Old.Level, c.New.Level))
+			case f == "message":
+				changes = append(changes, fmt.Sprintf("message %q -> %q", c.Old.Message, c.New.Message))
+			case f == "position":
+				changes = append(changes, fmt.Sprintf("position %s -> %s", positionString(c.Old.Position),
+					positionString(c.New.Position)))
+			case f == "retryable":
+				changes = append(changes, fmt.Sprintf("retryable %t -> %t", c.Old.Retryable, c.New.Retryable))
+			case f == "temporary":
+				changes = append(changes, fmt.Sprintf("temporary %t -> %t", c.Old.Temporary, c.New.Temporary))
+			case f == "retry_after":
+				changes = append(changes, fmt.Sprintf("retry_after %s -> %s", c.Old.RetryAfter, c.New.RetryAfter))
+			default:
+				k := strings.TrimPrefix(f, "metadata.")
+				changes = append(changes, fmt.Sprintf("%s %s -> %s", f,
+					diffValue(c.Old.Resolve().Metadata, k), diffValue(c.New.Resolve().Metadata, k)))
+			}
+		}
+
+		b.WriteString("~ " + name + ": " + strings.Join(changes, ", ") + "\n")
+	}
+
+	return b.String()
+}
+
+func diffValue(md map[string]string, key string) string {
+	v, ok := md[key]
+	if !ok {
+		return "<missing>"
+	}
+
+	return fmt.Sprintf("%q", v)
+}
+
+func positionString(p *Position) string {
+	if p == nil {
+		return "<missing>"
+	}
+
+	return p.String()
+}
@@ -0,0 +1,109 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffErrorsEqual(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	a := New()
+	a.Add(warnErr)
+	a.Add(errorErr)
+
+	b := New()
+	b.Add(NewError(WARN, testMessage, mdTypeKey, mdTypeVal, mdUserKey, mdUserVal))
+	b.Add(NewError(ERROR, testMessage, mdTypeKey, mdTypeVal, mdUserKey, mdUserVal))
+	require.True(t, a.Equal(b))
+
+	// Order matters unless Unordered.
+	c := New()
+	c.Add(errorErr)
+	c.Add(warnErr)
+	require.False(t, a.Equal(c))
+	require.True(t, a.Equal(c, Unordered()))
+
+	// Different lengths.
+	c.Add(debugErr)
+	require.False(t, a.Equal(c, Unordered()))
+
+	// Duplicates must match one for one.
+	d := New()
+	d.Add(warnErr)
+	d.Add(warnErr)
+	require.False(t, a.Equal(d, Unordered()))
+	require.False(t, d.Equal(a, Unordered()))
+
+	// Ignored metadata.
+	e := New()
+	e.Add(NewError(WARN, testMessage, mdTypeKey, mdTypeVal, mdUserKey, "someone else"))
+	e.Add(errorErr)
+	require.False(t, a.Equal(e))
+	require.True(t, a.Equal(e, IgnoreMetadata(mdUserKey)))
+}
+
+func TestDiff(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	a := New()
+	a.Add(debugErr)
+	a.Field("port", ERROR, "must be a number", "value", "abc")
+	a.NewError(WARN, "deprecated option", "option", "timeout")
+	a.NewError(ERROR, "connect failed", CodeKey, "E100", "host", "db1")
+
+	b := New()
+	b.NewError(ERROR, "connect refused", CodeKey, "E100", "host", "db2")
+	b.Field("port", WARN, "must be a number", "value", "abc", "hint", "use 8080")
+	b.Add(debugErr)
+	b.NewError(INFO, "new option", "option", "retries")
+
+	d := Diff(a, b)
+	require.False(t, d.IsEmpty())
+
+	require.Len(t, d.Removed, 1)
+	require.Equal(t, "deprecated option", d.Removed[0].Message)
+
+	require.Len(t, d.Added, 1)
+	require.Equal(t, "new option", d.Added[0].Message)
+
+	require.Len(t, d.Changed, 2)
+	require.Equal(t, []string{"level", "metadata.hint"}, d.Changed[0].Fields)
+	require.Equal(t, []string{"message", "metadata.host"}, d.Changed[1].Fields)
+
+	s := d.String()
+	require.Contains(t, s, `- {"time":`)
+	require.Contains(t, s, `+ {"time":`)
+	require.Contains(t, s, `~ "must be a number" at /port: level error -> warn, metadata.hint <missing> -> "use 8080"`)
+	require.Contains(t, s, `~ "connect failed": message "connect failed" -> "connect refused", metadata.host "db1" -> "db2"`)
+
+	j, err := json.Marshal(d)
+	require.Nil(t, err)
+	require.Contains(t, string(j), `"fields":["level","metadata.hint"]`)
+
+	var d2 ErrorsDiff
+	require.Nil(t, json.Unmarshal(j, &d2))
+	require.Len(t, d2.Changed, 2)
+
+	// No differences.
+	d = Diff(a, a)
+	require.True(t, d.IsEmpty())
+	require.Equal(t, "", d.String())
+	j, err = json.Marshal(d)
+	require.Nil(t, err)
+	require.Equal(t, "{}", string(j))
+
+	// Ignored metadata is not a change.
+	d = Diff(a, b, IgnoreMetadata("hint", "host"))
+	require.Equal(t, []string{"level"}, d.Changed[0].Fields)
+
+	// Errors in groups are compared too.
+	c := a.Clone()
+	c.Group("db").Add(infoErr)
+	require.False(t, a.Equal(c))
+	d = Diff(a, c)
+	require.Len(t, d.Added, 1)
+	require.Equal(t, "db", d.Added[0].Metadata[GroupKey])
+}
@@ -3,51 +3,89 @@ package jerrors
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"maps"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// CodeKey is the Metadata key used to store an Error's code.
+const CodeKey = "code"
+
 // Error holds our Level and Message data map.
+// Retryable marks failures that may succeed if tried again and Temporary failures expected to
+// clear up on their own, such as timeouts. RetryAfter is how long to wait before trying again and
+// is written to JSON as a duration string, such as "1.5s".
 type Error struct {
-	Time     *time.Time        `json:"time,omitempty"`
-	Level    Level             `json:"level,omitempty"`
-	Message  string            `json:"message,omitempty"`
-	Metadata map[string]string `json:"metadata,omitempty"`
+	Time       *time.Time        `json:"time,omitempty"`
+	Level      Level             `json:"level,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	Field      FieldPath         `json:"field,omitempty"`
+	Position   *Position         `json:"position,omitempty"`
+	Retryable  bool              `json:"retryable,omitempty"`
+	Temporary  bool              `json:"temporary,omitempty"`
+	RetryAfter time.Duration     `json:"-"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+
+	// keys holds the Metadata keys in the order they were added.
+	keys []string
+	// lazy holds the Metadata keys with Lazy values, computed by Resolve.
+	lazy []lazyMetadata
+	// sentinel is set on package sentinels such as ErrNoErrorFound and kept by copies of them.
+	sentinel *sentinelID
 }
 
 // NewError creates a new Error object and returns it.
 // args should be in the for of keyString1, valueString1,...
 func NewError(level Level, msg string, args ...interface{}) Error {
-	// Create a base error.
-	e := Error{Metadata: make(map[string]string)}
+	e := newError(level, msg, args...)
+	countCreated(e)
+	return e
+}
 
-	// Set the error level and message.
-	e.Level = level
-	e.Message = msg
+// newError creates the Error for NewError. It must be called directly by the exported function
+// creating the Error so the caller recorded is the one calling that function. The Error is not
+// counted, so callers can change it first and then call countCreated.
+func newError(level Level, msg string, args ...interface{}) Error {
+	// Create a base error.
+	e := Error{Level: level, Message: msg, Metadata: make(map[string]string)}
+	c := config.Load()
 
 	// Check if we should log the time.
-	if config.LogTime {
-		t := time.Now()
+	if c.LogTime {
+		t := now()
+		if c.UTC {
+			t = t.UTC()
+		}
 		e.Time = &t
 	}
 
 	// Check if we should log the caller.
-	if config.LogCaller {
-		e.Metadata["caller"] = getCaller()
+	if c.LogCaller {
+		e.setMetadata("caller", getCaller())
 	}
 
 	// Convert args to key value pairs
-	e.AddMetadata(args...)
+	e.addMetadata(args...)
 
 	return e
 }
 
-// Merge converts args into string pairs and adds them to the Error's Metadata.
+// AddMetadata converts args into string pairs and adds them to the Error's Metadata. The Metadata
+// is copied before it is changed, so copies of the Error sharing it are not affected. Pass all
+// pairs in one call rather than many calls with one pair each, since each call copies it.
 func (e *Error) AddMetadata(args ...interface{}) {
+	e.ownMetadata()
+	e.addMetadata(args...)
+}
+
+// addMetadata adds args to the Error's Metadata in place, making the map if there is none.
+func (e *Error) addMetadata(args ...interface{}) {
 	l := len(args)
+	if e.Metadata == nil && l > 1 {
+		e.Metadata = make(map[string]string, l/2)
+	}
 
 	for i, arg := range args {
 		if i%2 != 0 {
@@ -58,7 +96,7 @@ func (e *Error) AddMetadata(args ...interface{}) {
 			return
 		}
 
-		e.Metadata[fmt.Sprint(arg)] = fmt.Sprint(args[i+1])
+		e.putMetadata(argString(arg), args[i+1])
 
 		// If this was the last key, we're done.
 		if i+2 >= l {
@@ -67,9 +105,26 @@ func (e *Error) AddMetadata(args ...interface{}) {
 	}
 }
 
+// Code returns the Error's code stored in Metadata[CodeKey], computing it if it is Lazy. Returns
+// "" if no code is set.
+func (e Error) Code() string {
+	code, _ := e.GetMetadata(CodeKey)
+	return code
+}
+
 // Equal returns true if the Error is equal to the given Error. Equal does not compare Time.
+// Lazy Metadata values are computed and compared.
 func (e Error) Equal(error Error) bool {
-	if e.Level != error.Level || e.Message != error.Message {
+	e, error = e.Resolve(), error.Resolve()
+	if e.Level != error.Level || e.Message != error.Message || !e.Field.Equal(error.Field) {
+		return false
+	}
+
+	if !e.Position.Equal(error.Position) {
+		return false
+	}
+
+	if e.Retryable != error.Retryable || e.Temporary != error.Temporary || e.RetryAfter != error.RetryAfter {
 		return false
 	}
 
@@ -78,12 +133,17 @@ func (e Error) Equal(error Error) bool {
 
 // String returns the string representation of the Error.
 func (e Error) String() string {
-	if !config.LogLevel {
-		e.Level = 0
-	}
+	b := getBuffer()
+	defer putBuffer(b)
+
+	*b = e.appendLog(*b)
+	return string(*b)
+}
 
-	j, _ := json.Marshal(e)
-	return string(j)
+// appendLog appends the JSON written when e is logged, leaving out the Level if Config.LogLevel
+// is not set. The Fingerprint still includes the Level so it matches Error.Fingerprint.
+func (e Error) appendLog(b []byte) []byte {
+	return e.appendJSON(b, config.Load().LogLevel)
 }
 
 // Error returns the string representation of the Error.
@@ -91,24 +151,58 @@ func (e Error) Error() string {
 	return e.String()
 }
 
-/*
-// MarshalJSON converts Error to a json byte array.
-func (e *Error) MarshalJSON() ([]byte, error) {
-	if !logLevel {
-		e.Level = 0
-	}
-	j, _ := json.Marshal(e)
-	return j, nil
+// errorJSON is Error without its methods so it can be embedded in MarshalJSON and UnmarshalJSON.
+type errorJSON Error
+
+// MarshalJSON converts Error to json, writing Time with Config.TimeFormat. Adds the Fingerprint if
+// Config.LogFingerprint is set and the SchemaVersion if Config.LogSchemaVersion is set. See
+// AppendJSON.
+func (e Error) MarshalJSON() ([]byte, error) {
+	return e.AppendJSON(nil), nil
 }
-*/
 
-// UnmarshalJSON converts a json byte array to an Error.
-/*
+// UnmarshalJSON converts json to an Error. Time may be any Config.TimeFormat. The order of the
+// Metadata keys is kept.
 func (e *Error) UnmarshalJSON(b []byte) error {
-	err := json.Unmarshal(b, e)
-	return err
+	var j struct {
+		Time json.RawMessage `json:"time,omitempty"`
+		errorJSON
+		RetryAfter string          `json:"retry_after,omitempty"`
+		Metadata   json.RawMessage `json:"metadata,omitempty"`
+	}
+
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	*e = Error(j.errorJSON)
+	if len(j.Metadata) > 0 {
+		m, keys, err := decodeMetadataJSON(j.Metadata)
+		if err != nil {
+			return err
+		}
+		e.Metadata, e.keys = m, keys
+	}
+
+	if j.RetryAfter != "" {
+		d, err := time.ParseDuration(j.RetryAfter)
+		if err != nil {
+			return err
+		}
+		e.RetryAfter = d
+	}
+
+	e.Time = nil
+	if len(j.Time) > 0 && string(j.Time) != "null" {
+		t, err := parseTime(j.Time)
+		if err != nil {
+			return err
+		}
+		e.Time = &t
+	}
+
+	return nil
 }
-*/
 
 // IsError returns true for anything above WARN
 func (e *Error) IsError() bool {
@@ -120,32 +214,121 @@ func (e *Error) IsFatal() bool {
 	return e.Level.IsFatal()
 }
 
-// Log logs the error with the appropriate logger type.
+// Log logs the error if its Level is at least the Logging Level, or the package Level set with
+// SetPackageLevels for the package calling Log.
 func (e *Error) Log() {
-	if e.Level >= config.LoggingLevel {
-		log.Println(e)
+	if min, _ := minLevel(1); e.Level >= min && sampled(*e) {
+		e.log(2)
+		countLogged(*e)
 	}
 }
 
-// Fatal logs and exits as a fatal error.
+// Fatal logs the error as FATAL, runs the shutdown hooks and exits with ExitCode(e).
+// See SetExitFunc and OnShutdown.
 func (e *Error) Fatal() {
 	if len(e.Message) > 0 {
 		e.Level = FATAL
-		log.Fatalln(e)
+		e.log(2)
+		countLogged(*e)
+		Exit(ExitCode(*e))
 	}
 }
 
-func getCaller() string {
-	callers := make([]uintptr, 0, config.CallersToShow)
-	for i := config.CallerDepth; i <= config.CallerDepth+config.CallersToShow; i++ {
-		c, _, _, _ := runtime.Caller(i)
-		callers = append(callers, c)
+// log writes e to the standard logger, reporting the call site calldepth frames above the caller
+// of log. See output.
+func (e Error) log(calldepth int) {
+	b := getBuffer()
+	*b = e.appendLog(*b)
+	output(calldepth+1, *b)
+	putBuffer(b)
+}
+
+// argString converts a metadata arg to a string like fmt.Sprint, without fmt for common types.
+// Lazy args are computed first.
+func argString(arg interface{}) string {
+	if l, ok := arg.(Lazy); ok {
+		arg = l()
+	}
+
+	if s, ok := arg.(string); ok {
+		return s
+	}
+
+	var buf [32]byte
+	if b, ok := appendPlainArg(buf[:0], arg); ok {
+		return string(b)
 	}
+
+	return fmt.Sprint(arg)
+}
+
+// appendArgJSON appends argString(arg) as a JSON string.
+func appendArgJSON(b []byte, arg interface{}) []byte {
+	if l, ok := arg.(Lazy); ok {
+		arg = l()
+	}
+
+	if s, ok := arg.(string); ok {
+		return appendJSONString(b, s)
+	}
+
+	if plain, ok := appendPlainArg(append(b, '"'), arg); ok {
+		return append(plain, '"')
+	}
+
+	return appendJSONString(b, fmt.Sprint(arg))
+}
+
+// appendPlainArg appends a number or bool formatted like fmt.Sprint, which never needs JSON
+// escaping. Returns false for other types.
+func appendPlainArg(b []byte, arg interface{}) ([]byte, bool) {
+	switch v := arg.(type) {
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case int8:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case int16:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case int64:
+		return strconv.AppendInt(b, v, 10), true
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10), true
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10), true
+	case uint16:
+		return strconv.AppendUint(b, uint64(v), 10), true
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10), true
+	case uint64:
+		return strconv.AppendUint(b, v, 10), true
+	case float32:
+		return strconv.AppendFloat(b, float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.AppendFloat(b, v, 'g', -1, 64), true
+	case bool:
+		return strconv.AppendBool(b, v), true
+	}
+
+	return b, false
+}
+
+func getCaller() string {
+	c := config.Load()
+	callers := callerPCs(c.CallerDepth+1, c.CallersToShow)
 	frames := runtime.CallersFrames(callers)
-	s := make([]string, 0, config.CallersToShow)
-	for i := 0; i < config.CallersToShow; i++ {
+	s := make([]string, 0, c.CallersToShow)
+	for i := 0; i < c.CallersToShow; i++ {
 		f, _ := frames.Next()
 		s = append([]string{fmt.Sprintf("%s{%d}", f.Function, f.Line)}, s...)
 	}
 	return strings.Join(s, "->")
 }
+
+// callerPCs returns up to n program counters starting skip frames above the function calling
+// callerPCs. A skip of 0 is the function calling callerPCs.
+func callerPCs(skip, n int) []uintptr {
+	pcs := make([]uintptr, n)
+	return pcs[:runtime.Callers(skip+2, pcs)]
+}
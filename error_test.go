@@ -159,7 +159,10 @@ func TestErrorString(t *testing.T) {
 	require.Contains(t, s, `"level":"error","message":"test error","metadata":{"type":"test","user":"test1"}}`)
 
 	// LogLevel = false
-	config.LogLevel = false
+	c := DefaultConfig()
+	c.LogLevel = false
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
 	s = err.String()
 	require.Contains(t, s, `"time":"`)
 	require.NotContains(t, s, `"level":"error"`)
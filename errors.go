@@ -6,12 +6,28 @@ import (
 	"strings"
 )
 
-var ErrNoErrorFound = NewError(0, "No error found")
+// ErrNoErrorFound is returned by First and Last when Errors is empty. It has no Metadata map to
+// change, and errors.Is matches it by identity, so changing a copy never affects the check. Use
+// errors.Is to check for it.
+var ErrNoErrorFound = noErrorFound
 
-// Errors is a slice of Errors and with Level showing the highest error level added.
+// noErrorFound is the untouched ErrNoErrorFound returned by First and Last, so a caller
+// reassigning ErrNoErrorFound cannot change what they return.
+var noErrorFound = Error{Message: "No error found", sentinel: &sentinelID{"no error found"}}
+
+// sentinelID identifies a package sentinel Error for Error.Is. It is not zero sized so each
+// sentinel has its own address.
+type sentinelID struct{ name string }
+
+// Errors is a slice of Errors and with Level showing the highest error level added. Errors can hold
+// named child groups, see Group. The Level of a group bubbles up to its parents.
 type Errors struct {
-	Errors []Error `json:"errors"`
-	Level  Level   `json:"level"`
+	Name   string    `json:"name,omitempty"`
+	Errors []Error   `json:"errors"`
+	Level  Level     `json:"level"`
+	Groups []*Errors `json:"groups,omitempty"`
+
+	parent *Errors
 }
 
 func New() Errors {
@@ -28,18 +44,32 @@ func (e *Errors) NewError(level Level, msg string, args ...interface{}) {
 
 // Add an error to the method's List.
 func (e *Errors) Add(err Error) {
-	if err.Level > e.Level {
-		e.Level = err.Level
-	}
-
+	e.raise(err.Level)
 	e.Errors = append(e.Errors, err)
 }
 
+// Remove removes the first Error equal to error from the List or, if the List has none, from its
+// groups. Returns false if no Error was removed.
 func (e *Errors) Remove(error Error) bool {
+	if !e.remove(error) {
+		return false
+	}
+
+	e.root().UpdateLevel()
+	return true
+}
+
+// remove removes the first Error equal to error from e or its groups, depth first.
+func (e *Errors) remove(error Error) bool {
 	for i, err := range e.Errors {
 		if err.Equal(error) {
 			e.Errors = append(e.Errors[:i], e.Errors[i+1:]...)
-			e.UpdateLevel()
+			return true
+		}
+	}
+
+	for _, g := range e.Groups {
+		if g.remove(error) {
 			return true
 		}
 	}
@@ -47,7 +77,8 @@ func (e *Errors) Remove(error Error) bool {
 	return false
 }
 
-// UpdateLevel sets Errors.Level the the highest one in the Errors list and returns Errors.Level.
+// UpdateLevel sets Errors.Level the the highest one in the Errors list and its groups and returns
+// Errors.Level.
 func (e *Errors) UpdateLevel() Level {
 	var l Level
 	for _, err := range e.Errors {
@@ -56,40 +87,49 @@ func (e *Errors) UpdateLevel() Level {
 		}
 	}
 
+	for _, g := range e.Groups {
+		if gl := g.UpdateLevel(); gl > l {
+			l = gl
+		}
+	}
+
 	e.Level = l
 	return e.Level
 }
 
-// Check if Errors is not empty and return the number of Errors.
+// Check if Errors is not empty and return the number of Errors, including those in groups.
 func (e *Errors) Check() (int, bool) {
-	if l := len(e.Errors); l > 0 {
+	if l := e.Count(); l > 0 {
 		return l, true
 	}
 
 	return 0, false
 }
 
-// First returns the first (e.Errors[0]) Error in the Errors List. Reutnrs ErrNoErrorFound if
-// Errors.Errors is empt. ErrNoErrorFound = NewError(0, "No errors found")
+// First returns the first Error in the Errors List, as ordered by Flatten. Returns a copy of
+// ErrNoErrorFound if Errors is empty.
 func (e *Errors) First() Error {
-	if len(e.Errors) == 0 {
-		return ErrNoErrorFound
+	all := e.Flatten()
+	if len(all) == 0 {
+		return noErrorFound.Clone()
 	}
 
-	return e.Errors[0]
+	return all[0]
 }
 
-// Last returns the last Error in the Errors List. Returns ErrNoErrorFound if Errors.Errors is empty.
+// Last returns the last Error in the Errors List, as ordered by Flatten. Returns a copy of
+// ErrNoErrorFound if Errors is empty.
 func (e *Errors) Last() Error {
-	if len(e.Errors) == 0 {
-		return ErrNoErrorFound
+	all := e.Flatten()
+	if len(all) == 0 {
+		return noErrorFound.Clone()
 	}
 
-	return e.Errors[len(e.Errors)-1]
+	return all[len(all)-1]
 }
 
-// IsEmpty checks to see if Errors.Errors is empty.
-func (e *Errors) IsEmpty() bool { return len(e.Errors) == 0 }
+// IsEmpty checks to see if Errors.Errors and all groups are empty.
+func (e *Errors) IsEmpty() bool { return e.Count() == 0 }
 
 // IsError returns true for anything above WARN
 func (e *Errors) IsError() bool { return e.Level.IsError() }
@@ -97,63 +137,72 @@ func (e *Errors) IsError() bool { return e.Level.IsError() }
 // IsFatal returns true for anything above ERROR
 func (e *Errors) IsFatal() bool { return e.Level.IsFatal() }
 
-// SetLevel overrides the Level of the List
-func (e *Errors) SetLevel(level Level) { e.Level = level }
+// SetLevel overrides the Level of the List. Parent groups are raised to level if it is higher.
+func (e *Errors) SetLevel(level Level) {
+	e.Level = level
+	e.bubble()
+}
 
 // String is an alternate method name for List.Error()
 func (e *Errors) String() string { return e.Error() }
 
-// Clear the List and Level
-func (e *Errors) Clear() { *e = New() }
+// Clear the List, its groups and Level. The Name is kept and a group stays in its parent.
+func (e *Errors) Clear() {
+	name, parent := e.Name, e.parent
+	*e = New()
+	e.Name, e.parent = name, parent
+	if parent != nil {
+		parent.root().UpdateLevel()
+	}
+}
 
-// Stack adds the arg List to top of the method's List
+// Stack adds the arg List to top of the method's List. Groups are merged by name.
 func (e *Errors) Stack(errs Errors) {
+	e.addGroups(errs.Groups)
 	if len(errs.Errors) == 0 {
 		return
 	}
 
 	// If l is currently empty then overwrite it
-	if len(e.Errors) == 0 {
+	if len(e.Errors) == 0 && len(e.Groups) == 0 {
 		e.Level = errs.Level
 		e.Errors = errs.Errors
+		e.bubble()
 		return
 	}
 
-	if errs.Level > e.Level {
-		e.Level = errs.Level
-	}
-
+	e.raise(errs.Level)
 	e.Errors = append(errs.Errors, e.Errors...)
 }
 
-// Append the arg List to the method's List.
+// Append the arg List to the method's List. Groups are merged by name.
 func (e *Errors) Append(errs Errors) {
+	e.addGroups(errs.Groups)
 	if len(errs.Errors) == 0 {
 		return
 	}
 
 	// If l is currently empty then overwrite it.
-	if len(e.Errors) == 0 {
+	if len(e.Errors) == 0 && len(e.Groups) == 0 {
 		e.Level = errs.Level
 		e.Errors = errs.Errors
+		e.bubble()
 		return
 	}
 
-	if errs.Level > e.Level {
-		e.Level = errs.Level
-	}
-
+	e.raise(errs.Level)
 	e.Errors = append(e.Errors, errs.Errors...)
 }
 
+// toArray returns the Errors of the List and its groups, see Flatten.
 func (e *Errors) toArray(enforceLogLevel bool) []Error {
-	switch i := len(e.Errors); i {
+	switch all := e.Flatten(); len(all) {
 	case 0:
 		return []Error{}
 	default:
 		msgs := []Error{}
-		for _, err := range e.Errors {
-			if !enforceLogLevel && err.Level < config.LoggingLevel {
+		for _, err := range all {
+			if !enforceLogLevel && !loggingLevel.Enabled(err.Level) {
 				continue
 			}
 
@@ -163,15 +212,21 @@ func (e *Errors) toArray(enforceLogLevel bool) []Error {
 	}
 }
 
-// Error returns all errors in List as a single json string. Returns empty string if failed.
+// Error returns all errors in List as a single json string.
 func (e *Errors) Error() string {
-	msgs := e.toArray(false)
-	j, err := json.Marshal(msgs)
-	if err != nil {
-		return ""
+	b := getBuffer()
+	defer putBuffer(b)
+
+	*b = append(*b, '[')
+	for i, err := range e.toArray(false) {
+		if i > 0 {
+			*b = append(*b, ',')
+		}
+		*b = err.AppendJSON(*b)
 	}
+	*b = append(*b, ']')
 
-	return string(j)
+	return string(*b)
 }
 
 /*
@@ -241,18 +296,56 @@ func (e *Errors) ToLogArray() []string {
 	return a
 }
 
-// Log all messages in the List.
+// Log all messages in the List with a Level at least the Logging Level, or the package Level set
+// with SetPackageLevels for the package calling Log, like Error.Log.
 func (e *Errors) Log() {
-	if len(e.Errors) == 0 {
+	if e.IsEmpty() {
 		return
 	}
 
-	msgs := e.ToLogArray()
-	log.Print(strings.Join(msgs, "\n"))
+	min, _ := minLevel(1)
+
+	b := getBuffer()
+	defer putBuffer(b)
+
+	for _, err := range e.toArray(true) {
+		if err.Level >= min && sampled(err) {
+			if len(*b) > 0 {
+				*b = append(*b, '\n')
+			}
+			*b = err.appendLog(*b)
+			countLogged(err)
+		}
+	}
+
+	output(2, *b)
 }
 
-// Fatal converts all errors to a single error and runs Fatal to print error and exit(1).
+// Fatal converts all errors to a single error and prints it, then runs the shutdown hooks and
+// exits with ExitCode. See SetExitFunc and OnShutdown.
 func (e *Errors) Fatal(msg string) {
 	msgs := e.ToLogArray()
-	log.Fatal(msg + strings.Join(msgs, "\n"))
+	log.Default().Output(2, msg+strings.Join(msgs, "\n"))
+	for _, err := range e.Flatten() {
+		countLogged(err)
+	}
+	Exit(e.ExitCode())
+}
+
+// ExitCode returns the ExitCode of the first Error with the highest Level, including groups.
+// Returns 1 if Errors is empty.
+func (e *Errors) ExitCode() int {
+	all := e.Flatten()
+	if len(all) == 0 {
+		return 1
+	}
+
+	worst := all[0]
+	for _, err := range all[1:] {
+		if err.Level > worst.Level {
+			worst = err
+		}
+	}
+
+	return ExitCode(worst)
 }
@@ -47,6 +47,15 @@ func TestErrorsRemove(t *testing.T) {
 	errs.Remove(e)
 	require.Equal(t, 3, len(errs.Errors))
 	require.Equal(t, WARN, errs.Level)
+
+	// Errors in groups are removed from their group.
+	db := errs.Group("app").Group("db")
+	db.Add(errorErr)
+	require.Equal(t, ERROR, errs.Level)
+	require.True(t, errs.Remove(errorErr))
+	require.Empty(t, db.Errors)
+	require.Equal(t, WARN, errs.Level)
+	require.False(t, errs.Remove(errorErr))
 }
 
 func TestErrorsUpdateLevel(t *testing.T) {
@@ -300,6 +309,17 @@ func TestErrorsLog(t *testing.T) {
 	errs.Add(debugErr)
 	errs.Add(infoErr)
 
+	// Errors below the Logging Level are skipped.
+	errs.Log()
+	require.NotContains(t, buf.String(), "debug")
+	require.Contains(t, buf.String(), "info")
+
+	c := DefaultConfig()
+	c.LoggingLevel = DEBUG
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	buf.Reset()
 	errs.Log()
 	require.NotEmpty(t, buf)
 	require.Contains(t, buf.String(), "debug")
@@ -0,0 +1,163 @@
+package jerrors
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long Exit waits for shutdown hooks before exiting anyway.
+const DefaultShutdownTimeout = 5 * time.Second
+
+var (
+	exitMu          sync.RWMutex
+	exitFunc        = os.Exit
+	exitCodes       = map[Level]int{}
+	shutdownHooks   []func(context.Context)
+	shutdownTimeout = DefaultShutdownTimeout
+)
+
+// ExitPanic is the value PanicOnExit panics with. Recover it to test Fatal.
+type ExitPanic struct {
+	Code int
+}
+
+// PanicOnExit is an exit function for tests. It panics with ExitPanic instead of exiting.
+// Example:
+// jerrors.SetExitFunc(jerrors.PanicOnExit)
+// defer jerrors.SetExitFunc(nil)
+// require.PanicsWithValue(t, jerrors.ExitPanic{Code: 1}, err.Fatal)
+func PanicOnExit(code int) { panic(ExitPanic{Code: code}) }
+
+// ExitRecorder records exit calls instead of exiting. Use ExitRecorder.Exit with SetExitFunc.
+type ExitRecorder struct {
+	mu    sync.Mutex
+	codes []int
+}
+
+// Exit records code.
+func (r *ExitRecorder) Exit(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codes = append(r.codes, code)
+}
+
+// Codes returns the recorded exit codes in the order Exit was called.
+func (r *ExitRecorder) Codes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]int{}, r.codes...)
+}
+
+// Called returns true if Exit has been called.
+func (r *ExitRecorder) Called() bool { return len(r.Codes()) > 0 }
+
+// SetExitFunc sets the function Fatal uses to exit. Pass nil to restore os.Exit.
+func SetExitFunc(f func(code int)) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	if f == nil {
+		f = os.Exit
+	}
+
+	exitFunc = f
+}
+
+// SetExitCodes sets the exit code used for each Level. Levels without a code exit with 1.
+func SetExitCodes(codes map[Level]int) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	exitCodes = make(map[Level]int, len(codes))
+	for l, c := range codes {
+		exitCodes[l] = c
+	}
+}
+
+// SetShutdownTimeout sets how long Exit waits for shutdown hooks. 0 restores the default.
+func SetShutdownTimeout(d time.Duration) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	if d <= 0 {
+		d = DefaultShutdownTimeout
+	}
+
+	shutdownTimeout = d
+}
+
+// OnShutdown registers a hook to run before Exit exits, such as flushing output or closing a
+// database. Hooks run in reverse order of registration, like defers. The context is cancelled
+// when the shutdown timeout is reached.
+func OnShutdown(hook func(ctx context.Context)) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	shutdownHooks = append(shutdownHooks, hook)
+}
+
+// ClearShutdownHooks removes all registered shutdown hooks.
+func ClearShutdownHooks() {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	shutdownHooks = nil
+}
+
+// ExitCode returns the code the process should exit with for e. A numeric Code between 1 and
+// 125 is used as is, otherwise the code set for e.Level with SetExitCodes. Defaults to 1.
+func ExitCode(e Error) int {
+	if c, err := strconv.Atoi(e.Code()); err == nil && c > 0 && c < 126 {
+		return c
+	}
+
+	exitMu.RLock()
+	defer exitMu.RUnlock()
+
+	if c, ok := exitCodes[e.Level]; ok {
+		return c
+	}
+
+	return 1
+}
+
+// Exit flushes the active Sampler, runs the shutdown hooks, waiting at most the shutdown timeout,
+// and then exits with code using the exit function. The Sampler is flushed first so its last
+// summaries reach the outputs the hooks flush and close.
+func Exit(code int) {
+	exitMu.RLock()
+	hooks := append([]func(context.Context){}, shutdownHooks...)
+	timeout := shutdownTimeout
+	exit := exitFunc
+	exitMu.RUnlock()
+
+	if s := GetSampler(); s != nil {
+		s.Flush(true)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if ctx.Err() != nil {
+				return
+			}
+			hooks[i](ctx)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	exit(code)
+}
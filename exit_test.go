@@ -0,0 +1,151 @@
+package jerrors
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setTestExit(t *testing.T) *ExitRecorder {
+	r := &ExitRecorder{}
+	SetExitFunc(r.Exit)
+	t.Cleanup(func() {
+		SetExitFunc(nil)
+		SetExitCodes(nil)
+		SetShutdownTimeout(0)
+		ClearShutdownHooks()
+	})
+
+	return r
+}
+
+func TestExitPanicOnExit(t *testing.T) {
+	SetConfig(DefaultConfig())
+	setTestExit(t)
+	SetExitFunc(PanicOnExit)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	err := errorErr
+	require.PanicsWithValue(t, ExitPanic{Code: 1}, err.Fatal)
+	require.Contains(t, buf.String(), `"level":"fatal"`)
+}
+
+func TestExitRecorder(t *testing.T) {
+	SetConfig(DefaultConfig())
+	r := setTestExit(t)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	// No message, no exit.
+	e := Error{}
+	e.Fatal()
+	require.False(t, r.Called())
+
+	err := errorErr
+	err.Fatal()
+	require.True(t, r.Called())
+	require.Equal(t, []int{1}, r.Codes())
+
+	errs := New()
+	errs.Add(warnErr)
+	errs.Add(errorErr)
+	errs.Fatal("fatal: ")
+	require.Equal(t, []int{1, 1}, r.Codes())
+	require.Contains(t, buf.String(), "fatal: ")
+}
+
+func TestExitCode(t *testing.T) {
+	setTestExit(t)
+
+	require.Equal(t, 1, ExitCode(errorErr))
+
+	SetExitCodes(map[Level]int{ERROR: 3, FATAL: 4})
+	require.Equal(t, 3, ExitCode(errorErr))
+	require.Equal(t, 4, ExitCode(fatalErr))
+	require.Equal(t, 1, ExitCode(warnErr))
+
+	// Numeric codes win over the Level.
+	require.Equal(t, 42, ExitCode(NewError(ERROR, testMessage, CodeKey, "42")))
+	require.Equal(t, 3, ExitCode(NewError(ERROR, testMessage, CodeKey, "E42")))
+	require.Equal(t, 3, ExitCode(NewError(ERROR, testMessage, CodeKey, "255")))
+
+	errs := New()
+	require.Equal(t, 1, errs.ExitCode())
+
+	errs.Add(warnErr)
+	errs.Add(NewError(FATAL, testMessage, CodeKey, "7"))
+	errs.Add(fatalErr)
+	require.Equal(t, 7, errs.ExitCode())
+}
+
+func TestExitShutdownHooks(t *testing.T) {
+	r := setTestExit(t)
+
+	var order []int
+	OnShutdown(func(ctx context.Context) { order = append(order, 1) })
+	OnShutdown(func(ctx context.Context) { order = append(order, 2) })
+
+	Exit(5)
+	require.Equal(t, []int{2, 1}, order)
+	require.Equal(t, []int{5}, r.Codes())
+}
+
+// closingBuffer is an output that drops writes once it is closed.
+type closingBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closingBuffer) Write(p []byte) (int, error) {
+	if b.closed {
+		return 0, os.ErrClosed
+	}
+
+	return b.Buffer.Write(p)
+}
+
+func TestExitFlushesSamplerBeforeHooks(t *testing.T) {
+	SetConfig(DefaultConfig())
+	r := setTestExit(t)
+
+	out := &closingBuffer{}
+	SetLogOutput(out)
+	defer SetLogOutput(nil)
+
+	SetSampler(NewSampler(SamplerConfig{Interval: time.Hour, Default: SamplePolicy{First: 1}}))
+	defer SetSampler(nil)
+	for i := 0; i < 3; i++ {
+		e := NewError(ERROR, testMessage)
+		e.Log()
+	}
+
+	OnShutdown(func(ctx context.Context) { out.closed = true })
+
+	Exit(1)
+	require.Equal(t, []int{1}, r.Codes())
+	require.Contains(t, out.String(), `"message":"suppressed 2 similar errors"`)
+}
+
+func TestExitShutdownTimeout(t *testing.T) {
+	r := setTestExit(t)
+	SetShutdownTimeout(10 * time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	OnShutdown(func(ctx context.Context) { <-release })
+
+	start := time.Now()
+	Exit(2)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, []int{2}, r.Codes())
+}
@@ -0,0 +1,191 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pointerEscaper and pointerUnescaper handle the RFC 6901 escapes for '~' and '/'.
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// PathSegment is a single step in a FieldPath. It is either a Name or an Index.
+type PathSegment struct {
+	Name    string
+	Index   int
+	IsIndex bool
+}
+
+// FieldPath is the structured path of the field an Error applies to, such as
+// user.addresses[2].zip. FieldPath is marshalled to JSON as an RFC 6901 JSON Pointer.
+type FieldPath []PathSegment
+
+// ParseFieldPath parses a dotted path with bracketed indexes such as "user.addresses[2].zip".
+// Brackets that do not hold a non-negative integer are treated as part of the name.
+func ParseFieldPath(path string) FieldPath {
+	var p FieldPath
+	if path == "" {
+		return p
+	}
+
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if rest == "" {
+			p = append(p, PathSegment{Name: part})
+			continue
+		}
+
+		var idxs []int
+		ok := true
+		for _, r := range strings.Split(rest, "[") {
+			i, err := strconv.Atoi(strings.TrimSuffix(r, "]"))
+			if !strings.HasSuffix(r, "]") || err != nil || i < 0 {
+				ok = false
+				break
+			}
+			idxs = append(idxs, i)
+		}
+
+		if !ok {
+			p = append(p, PathSegment{Name: part})
+			continue
+		}
+
+		if name != "" {
+			p = append(p, PathSegment{Name: name})
+		}
+		for _, i := range idxs {
+			p = append(p, PathSegment{Index: i, IsIndex: true})
+		}
+	}
+
+	return p
+}
+
+// ParsePointer parses an RFC 6901 JSON Pointer such as "/user/addresses/2/zip". Numeric
+// segments become indexes.
+func ParsePointer(pointer string) FieldPath {
+	var p FieldPath
+	if pointer == "" {
+		return p
+	}
+
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if i, err := strconv.Atoi(part); err == nil && i >= 0 && strconv.Itoa(i) == part {
+			p = append(p, PathSegment{Index: i, IsIndex: true})
+			continue
+		}
+
+		p = append(p, PathSegment{Name: pointerUnescaper.Replace(part)})
+	}
+
+	return p
+}
+
+// String returns the path in dotted form. user.addresses[2].zip
+func (p FieldPath) String() string {
+	var b strings.Builder
+	for i, s := range p {
+		if s.IsIndex {
+			b.WriteString("[" + strconv.Itoa(s.Index) + "]")
+			continue
+		}
+
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(s.Name)
+	}
+
+	return b.String()
+}
+
+// Pointer returns the path as an RFC 6901 JSON Pointer. /user/addresses/2/zip
+func (p FieldPath) Pointer() string {
+	var b strings.Builder
+	for _, s := range p {
+		b.WriteString("/")
+		if s.IsIndex {
+			b.WriteString(strconv.Itoa(s.Index))
+			continue
+		}
+		b.WriteString(pointerEscaper.Replace(s.Name))
+	}
+
+	return b.String()
+}
+
+// Join returns a new FieldPath with other appended to p.
+func (p FieldPath) Join(other FieldPath) FieldPath {
+	return append(slices.Clip(p), other...)
+}
+
+// Equal returns true if both paths have the same segments.
+func (p FieldPath) Equal(other FieldPath) bool { return slices.Equal(p, other) }
+
+// MarshalJSON converts FieldPath to a JSON Pointer string.
+func (p FieldPath) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Pointer())
+}
+
+// UnmarshalJSON converts a JSON Pointer string to a FieldPath.
+func (p *FieldPath) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*p = ParsePointer(s)
+	return nil
+}
+
+// Field creates a new Error for the field at path and adds it to the List. path is a dotted
+// path with bracketed indexes such as "user.addresses[2].zip".
+func (e *Errors) Field(path string, level Level, msg string, args ...interface{}) {
+	err := NewError(level, msg, args...)
+	err.Field = ParseFieldPath(path)
+	e.Add(err)
+}
+
+// Sub returns a FieldScope that prefixes every field path added through it with path.
+func (e *Errors) Sub(path string) *FieldScope {
+	return &FieldScope{errs: e, prefix: ParseFieldPath(path)}
+}
+
+// ByField groups the Errors, including those in groups as returned by Flatten, by the JSON
+// Pointer of their Field. Errors without a Field are grouped under "". The result can be passed
+// to json.Marshal for frontends to map errors to form inputs.
+func (e *Errors) ByField() map[string][]Error {
+	m := make(map[string][]Error)
+	for _, err := range e.Flatten() {
+		p := err.Field.Pointer()
+		m[p] = append(m[p], err)
+	}
+
+	return m
+}
+
+// FieldScope adds field Errors to an Errors with a common path prefix.
+type FieldScope struct {
+	errs   *Errors
+	prefix FieldPath
+}
+
+// Field creates a new Error for the field at the scope's prefix joined with path.
+func (s *FieldScope) Field(path string, level Level, msg string, args ...interface{}) {
+	err := NewError(level, msg, args...)
+	err.Field = s.prefix.Join(ParseFieldPath(path))
+	s.errs.Add(err)
+}
+
+// Sub returns a nested FieldScope with path appended to the prefix.
+func (s *FieldScope) Sub(path string) *FieldScope {
+	return &FieldScope{errs: s.errs, prefix: s.prefix.Join(ParseFieldPath(path))}
+}
+
+// Path returns the scope's prefix.
+func (s *FieldScope) Path() FieldPath { return s.prefix }
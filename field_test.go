@@ -0,0 +1,109 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldParseFieldPath(t *testing.T) {
+	p := ParseFieldPath("user.addresses[2].zip")
+	require.Equal(t, FieldPath{
+		{Name: "user"},
+		{Name: "addresses"},
+		{Index: 2, IsIndex: true},
+		{Name: "zip"},
+	}, p)
+	require.Equal(t, "user.addresses[2].zip", p.String())
+	require.Equal(t, "/user/addresses/2/zip", p.Pointer())
+
+	// Nested indexes.
+	p = ParseFieldPath("matrix[1][3]")
+	require.Equal(t, "/matrix/1/3", p.Pointer())
+	require.Equal(t, "matrix[1][3]", p.String())
+
+	// Brackets without an index are part of the name.
+	p = ParseFieldPath("a[b].c")
+	require.Equal(t, FieldPath{{Name: "a[b]"}, {Name: "c"}}, p)
+
+	require.Nil(t, ParseFieldPath(""))
+}
+
+func TestFieldPointer(t *testing.T) {
+	p := FieldPath{{Name: "a/b"}, {Name: "m~n"}, {Index: 0, IsIndex: true}}
+	require.Equal(t, "/a~1b/m~0n/0", p.Pointer())
+	require.Equal(t, p, ParsePointer(p.Pointer()))
+
+	require.Nil(t, ParsePointer(""))
+	require.Equal(t, FieldPath{{Name: "01"}}, ParsePointer("/01"))
+}
+
+func TestFieldJSON(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	errs := New()
+	errs.Field("user.addresses[2].zip", ERROR, "invalid zip", "value", "abc")
+	b, err := json.Marshal(errs.First())
+	require.Nil(t, err)
+	require.Contains(t, string(b), `"message":"invalid zip","field":"/user/addresses/2/zip","metadata"`)
+
+	var e Error
+	require.Nil(t, json.Unmarshal(b, &e))
+	require.True(t, e.Equal(errs.First()))
+
+	// No field is omitted.
+	b, err = json.Marshal(errorErr)
+	require.Nil(t, err)
+	require.NotContains(t, string(b), "field")
+}
+
+func TestFieldEqual(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	errs := New()
+	errs.Field("name", ERROR, "required")
+	errs.Field("email", ERROR, "required")
+	require.False(t, errs.Errors[0].Equal(errs.Errors[1]))
+
+	require.True(t, errs.Remove(errs.Errors[1]))
+	require.Equal(t, 1, len(errs.Errors))
+	require.Equal(t, "name", errs.First().Field.String())
+}
+
+func TestFieldSub(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	errs := New()
+	user := errs.Sub("user")
+	user.Field("name", WARN, "too short")
+
+	addr := user.Sub("addresses[2]")
+	addr.Field("zip", ERROR, "invalid zip")
+	addr.Field("", ERROR, "invalid address")
+	require.Equal(t, "user.addresses[2]", addr.Path().String())
+
+	require.Equal(t, 3, len(errs.Errors))
+	require.Equal(t, ERROR, errs.Level)
+	require.Equal(t, "/user/name", errs.Errors[0].Field.Pointer())
+	require.Equal(t, "/user/addresses/2/zip", errs.Errors[1].Field.Pointer())
+	require.Equal(t, "/user/addresses/2", errs.Errors[2].Field.Pointer())
+}
+
+func TestFieldByField(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	errs := New()
+	errs.Field("email", ERROR, "required")
+	errs.Field("email", WARN, "looks like a typo")
+	errs.Field("age", ERROR, "must be a number")
+	errs.NewError(ERROR, "form rejected")
+	errs.Group("address").Field("email", ERROR, "already used")
+
+	m := errs.ByField()
+	require.Len(t, m, 3)
+	require.Len(t, m["/email"], 3)
+	require.Equal(t, "address", m["/email"][2].Metadata[GroupKey])
+	require.Len(t, m["/age"], 1)
+	require.Equal(t, "form rejected", m[""][0].Message)
+}
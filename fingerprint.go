@@ -0,0 +1,59 @@
+package jerrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// Fingerprint returns a stable hash identifying the kind of failure e is, so identical failures
+// can be grouped across hosts and releases. It is built from the Level, the Code (or Message if
+// there is no Code), the Field, the Config.FingerprintKeys Metadata and, if
+// Config.FingerprintCaller is set, the function that created e. Time and all other Metadata are
+// ignored.
+func (e Error) Fingerprint() string {
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	write(e.Level.String())
+	if code := e.Code(); code != "" {
+		write("code:" + code)
+	} else {
+		write("message:" + e.Message)
+	}
+	write(e.Field.Pointer())
+
+	c := config.Load()
+	keys := append([]string{}, c.FingerprintKeys...)
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v, ok := e.GetMetadata(k); ok {
+			write(k + "=" + v)
+		}
+	}
+
+	if c.FingerprintCaller {
+		caller, _ := e.GetMetadata("caller")
+		write(topCaller(caller))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// topCaller returns the function that created an Error from its caller Metadata, without the line
+// number so it is stable between releases. "main.main{12}->main.run{40}" returns "main.run".
+func topCaller(caller string) string {
+	if i := strings.LastIndex(caller, "->"); i >= 0 {
+		caller = caller[i+2:]
+	}
+
+	if i := strings.LastIndex(caller, "{"); i >= 0 {
+		caller = caller[:i]
+	}
+
+	return caller
+}
@@ -0,0 +1,120 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprintStable(t *testing.T) {
+	SetConfig(DefaultConfig())
+	clock := setTestClock(t, testTime)
+
+	e1 := NewError(ERROR, testMessage, "user", "1")
+	clock.Add(time.Hour)
+	e2 := NewError(ERROR, testMessage, "user", "2")
+
+	require.Len(t, e1.Fingerprint(), 16)
+	require.Equal(t, e1.Fingerprint(), e2.Fingerprint())
+}
+
+func TestFingerprintDiffers(t *testing.T) {
+	SetConfig(DefaultConfig())
+	e := Error{Level: ERROR, Message: testMessage}
+
+	warn := e
+	warn.Level = WARN
+	require.NotEqual(t, e.Fingerprint(), warn.Fingerprint())
+
+	msg := e
+	msg.Message = "other message"
+	require.NotEqual(t, e.Fingerprint(), msg.Fingerprint())
+
+	field := e
+	field.Field = FieldPath{{Name: "port"}}
+	require.NotEqual(t, e.Fingerprint(), field.Fingerprint())
+}
+
+func TestFingerprintCode(t *testing.T) {
+	SetConfig(DefaultConfig())
+	e1 := Error{Level: ERROR, Message: "user 1 not found", Metadata: map[string]string{CodeKey: "not_found"}}
+	e2 := Error{Level: ERROR, Message: "user 2 not found", Metadata: map[string]string{CodeKey: "not_found"}}
+	require.Equal(t, e1.Fingerprint(), e2.Fingerprint())
+
+	e3 := NewError(ERROR, "user 3 not found", CodeKey, Lazy(func() any { return "not_found" }))
+	require.Equal(t, "not_found", e3.Code())
+	require.Equal(t, e1.Fingerprint(), e3.Fingerprint())
+}
+
+func TestFingerprintKeys(t *testing.T) {
+	c := DefaultConfig()
+	c.FingerprintKeys = []string{"table"}
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	e1 := Error{Level: ERROR, Message: testMessage, Metadata: map[string]string{"table": "users", "id": "1"}}
+	e2 := Error{Level: ERROR, Message: testMessage, Metadata: map[string]string{"table": "users", "id": "2"}}
+	e3 := Error{Level: ERROR, Message: testMessage, Metadata: map[string]string{"table": "orders", "id": "1"}}
+	require.Equal(t, e1.Fingerprint(), e2.Fingerprint())
+	require.NotEqual(t, e1.Fingerprint(), e3.Fingerprint())
+
+	// Lazy values are computed.
+	lazy := NewError(ERROR, testMessage, "table", Lazy(func() any { return "users" }), "id", "3")
+	lazy.Time = nil
+	require.Equal(t, e1.Fingerprint(), lazy.Fingerprint())
+}
+
+func TestFingerprintCaller(t *testing.T) {
+	c := DefaultConfig()
+	c.FingerprintCaller = true
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	e1 := Error{Level: ERROR, Message: testMessage, Metadata: map[string]string{"caller": "main.main{12}->main.run{40}"}}
+	e2 := Error{Level: ERROR, Message: testMessage, Metadata: map[string]string{"caller": "main.main{15}->main.run{42}"}}
+	e3 := Error{Level: ERROR, Message: testMessage, Metadata: map[string]string{"caller": "main.main{12}->main.load{8}"}}
+	require.Equal(t, e1.Fingerprint(), e2.Fingerprint())
+	require.NotEqual(t, e1.Fingerprint(), e3.Fingerprint())
+}
+
+func TestFingerprintTopCaller(t *testing.T) {
+	require.Equal(t, "main.run", topCaller("main.main{12}->main.run{40}"))
+	require.Equal(t, "main.main", topCaller("main.main{12}"))
+	require.Equal(t, "", topCaller(""))
+}
+
+func TestFingerprintJSON(t *testing.T) {
+	e := Error{Level: ERROR, Message: testMessage}
+
+	SetConfig(DefaultConfig())
+	b, err := json.Marshal(e)
+	require.Nil(t, err)
+	require.NotContains(t, string(b), "fingerprint")
+
+	c := DefaultConfig()
+	c.LogFingerprint = true
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	b, err = json.Marshal(e)
+	require.Nil(t, err)
+	require.Equal(t, `{"level":"error","message":"`+testMessage+`","fingerprint":"`+e.Fingerprint()+`"}`, string(b))
+
+	var got Error
+	require.Nil(t, json.Unmarshal(b, &got))
+	require.True(t, e.Equal(got))
+}
+
+func TestFingerprintLogNoLevel(t *testing.T) {
+	c := DefaultConfig()
+	c.LogFingerprint = true
+	c.LogLevel = false
+	c.LogTime = false
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	e := Error{Level: ERROR, Message: testMessage}
+	require.Equal(t, `{"message":"`+testMessage+`","fingerprint":"`+e.Fingerprint()+`"}`, e.String())
+}
@@ -0,0 +1,190 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GroupKey is the Metadata key Flatten stores the dotted path of an Error's group in.
+const GroupKey = "group"
+
+// Group returns the child group named name, creating it if it does not exist. Errors added to the
+// group raise the Level of the group and all of its parents. Copies of an Errors share their
+// groups, so Group links the returned group to e; reach groups through Group rather than keeping
+// them after copying their parent.
+// Example:
+// errs := jerrors.New()
+// errs.Group("app.yaml").Group("server").NewError(jerrors.ERROR, "port must be a number")
+func (e *Errors) Group(name string) *Errors {
+	for _, g := range e.Groups {
+		if g.Name == name {
+			g.parent = e
+			return g
+		}
+	}
+
+	g := &Errors{Name: name, Errors: []Error{}, parent: e}
+	e.Groups = append(e.Groups, g)
+	return g
+}
+
+// AddGroup adds errs as the child group named name and returns it. If the group exists errs is
+// appended to it.
+func (e *Errors) AddGroup(name string, errs Errors) *Errors {
+	g := e.Group(name)
+	g.Append(errs)
+	return g
+}
+
+// Parent returns the group containing e, or nil if e is not a group.
+func (e *Errors) Parent() *Errors { return e.parent }
+
+// Path returns the dotted path of the group from the root, such as "app.yaml.server". Returns ""
+// for the root.
+func (e *Errors) Path() string {
+	if e.parent == nil {
+		return ""
+	}
+
+	if p := e.parent.Path(); p != "" {
+		return p + "." + e.Name
+	}
+
+	return e.Name
+}
+
+// Count returns the number of Errors in the List and all of its groups.
+func (e *Errors) Count() int {
+	n := len(e.Errors)
+	for _, g := range e.Groups {
+		n += g.Count()
+	}
+
+	return n
+}
+
+// Flatten returns the Errors of the List followed by those of each group, depth first. Errors
+// from groups are copies with the dotted path of their group, relative to e, in
+// Metadata[GroupKey].
+func (e *Errors) Flatten() []Error {
+	if len(e.Groups) == 0 {
+		return e.Errors
+	}
+
+	all := make([]Error, 0, e.Count())
+	all = append(all, e.Errors...)
+	for _, g := range e.Groups {
+		g.flatten(g.Name, &all)
+	}
+
+	return all
+}
+
+func (e *Errors) flatten(path string, all *[]Error) {
+	for _, err := range e.Errors {
+		c := err.Clone()
+		c.setMetadata(GroupKey, path)
+		*all = append(*all, c)
+	}
+
+	for _, g := range e.Groups {
+		g.flatten(path+"."+g.Name, all)
+	}
+}
+
+// Tree renders the List as an indented tree. Each group is shown as "name (count)", with count
+// the number of Errors under it, followed by its Errors as "level: message", indented two spaces
+// deeper than its parent.
+func (e *Errors) Tree() string {
+	var b strings.Builder
+	e.tree(&b, 0)
+	return b.String()
+}
+
+func (e *Errors) tree(b *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	name := e.Name
+	if name == "" {
+		name = "errors"
+	}
+	fmt.Fprintf(b, "%s%s (%d)\n", indent, name, e.Count())
+
+	for _, err := range e.Errors {
+		fmt.Fprintf(b, "%s  %s: %s", indent, err.Level, err.Message)
+		if len(err.Field) > 0 {
+			b.WriteString(" at " + err.Field.Pointer())
+		}
+		b.WriteString("\n")
+	}
+
+	for _, g := range e.Groups {
+		g.tree(b, depth+1)
+	}
+}
+
+// MarshalJSON converts an Errors to json. A nil Errors list is written as [] so the zero Errors
+// matches the schema.
+func (e Errors) MarshalJSON() ([]byte, error) {
+	type errorsJSON Errors
+	j := errorsJSON(e)
+	if j.Errors == nil {
+		j.Errors = []Error{}
+	}
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON converts json to an Errors, linking each group to its parent.
+func (e *Errors) UnmarshalJSON(b []byte) error {
+	type errorsJSON Errors
+	var j errorsJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	parent := e.parent
+	*e = Errors(j)
+	e.parent = parent
+	for _, g := range e.Groups {
+		g.parent = e
+	}
+
+	return nil
+}
+
+// root returns the top of the tree e is in.
+func (e *Errors) root() *Errors {
+	for e.parent != nil {
+		e = e.parent
+	}
+
+	return e
+}
+
+// raise sets the Level to l if it is higher, then raises the parents.
+func (e *Errors) raise(l Level) {
+	if l > e.Level {
+		e.Level = l
+	}
+
+	e.bubble()
+}
+
+// bubble raises the Level of each parent to the Level of its child.
+func (e *Errors) bubble() {
+	for c, p := e, e.parent; p != nil; c, p = p, p.parent {
+		if c.Level <= p.Level {
+			return
+		}
+
+		p.Level = c.Level
+	}
+}
+
+// addGroups merges groups into e by name.
+func (e *Errors) addGroups(groups []*Errors) {
+	for _, g := range groups {
+		e.AddGroup(g.Name, *g)
+	}
+}
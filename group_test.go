@@ -0,0 +1,189 @@
+package jerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testGroups() Errors {
+	errs := New()
+	errs.NewError(INFO, "loading config")
+	server := errs.Group("app.yaml").Group("server")
+	server.Field("port", ERROR, "must be a number")
+	server.NewError(WARN, "host is empty")
+	errs.Group("app.yaml").Group("db").NewError(DEBUG, "using defaults")
+	return errs
+}
+
+func TestGroupLevelBubbles(t *testing.T) {
+	SetConfig(DefaultConfig())
+	errs := New()
+	app := errs.Group("app.yaml")
+	server := app.Group("server")
+
+	server.NewError(WARN, testMessage)
+	require.Equal(t, WARN, server.Level)
+	require.Equal(t, WARN, app.Level)
+	require.Equal(t, WARN, errs.Level)
+
+	app.Group("db").NewError(FATAL, testMessage)
+	require.True(t, errs.IsFatal())
+	require.Equal(t, WARN, server.Level)
+
+	db := app.Group("db")
+	require.True(t, db.Remove(db.Errors[0]))
+	require.Equal(t, WARN, errs.Level)
+
+	server.Clear()
+	require.Equal(t, Level(0), errs.Level)
+	require.Same(t, server, app.Group("server"))
+	require.Equal(t, "server", server.Name)
+}
+
+func TestGroupPathCount(t *testing.T) {
+	SetConfig(DefaultConfig())
+	errs := testGroups()
+
+	server := errs.Group("app.yaml").Group("server")
+	require.Equal(t, "app.yaml.server", server.Path())
+	require.Equal(t, "", errs.Path())
+	require.Same(t, errs.Group("app.yaml"), server.Parent())
+	require.Nil(t, errs.Parent())
+
+	require.Equal(t, 4, errs.Count())
+	n, ok := errs.Check()
+	require.Equal(t, 4, n)
+	require.True(t, ok)
+	require.False(t, errs.IsEmpty())
+
+	empty := New()
+	empty.Group("a").Group("b")
+	require.True(t, empty.IsEmpty())
+	require.Equal(t, ErrNoErrorFound, empty.First())
+}
+
+func TestGroupFlatten(t *testing.T) {
+	SetConfig(DefaultConfig())
+	errs := testGroups()
+
+	all := errs.Flatten()
+	require.Len(t, all, 4)
+	require.Equal(t, "loading config", all[0].Message)
+	require.NotContains(t, all[0].Metadata, GroupKey)
+	require.Equal(t, "app.yaml.server", all[1].Metadata[GroupKey])
+	require.Equal(t, "app.yaml.server", all[2].Metadata[GroupKey])
+	require.Equal(t, "app.yaml.db", all[3].Metadata[GroupKey])
+
+	// Flatten copies Errors from groups.
+	require.NotContains(t, errs.Group("app.yaml").Group("server").Errors[0].Metadata, GroupKey)
+
+	// Paths are relative to the receiver.
+	require.Equal(t, "server", errs.Group("app.yaml").Flatten()[0].Metadata[GroupKey])
+
+	require.Equal(t, "using defaults", errs.Last().Message)
+	require.Equal(t, ERROR, errs.Group("app.yaml").First().Level)
+}
+
+func TestGroupLog(t *testing.T) {
+	c := DefaultConfig()
+	c.LoggingLevel = DEBUG
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	errs := testGroups()
+
+	buf := new(bytes.Buffer)
+	SetLogOutput(buf)
+	defer SetLogOutput(nil)
+
+	errs.Log()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Contains(t, lines[1], `"group":"app.yaml.server"`)
+	require.Contains(t, lines[3], `"group":"app.yaml.db"`)
+
+	SetExitCodes(map[Level]int{ERROR: 3})
+	defer SetExitCodes(nil)
+	require.Equal(t, 3, errs.ExitCode())
+}
+
+func TestGroupTree(t *testing.T) {
+	SetConfig(DefaultConfig())
+	errs := testGroups()
+
+	want := `errors (4)
+  info: loading config
+  app.yaml (3)
+    server (2)
+      error: must be a number at /port
+      warn: host is empty
+    db (1)
+      debug: using defaults
+`
+	require.Equal(t, want, errs.Tree())
+}
+
+func TestGroupJSON(t *testing.T) {
+	c := DefaultConfig()
+	c.LogTime = false
+	c.LogCaller = false
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	errs := New()
+	errs.Group("app.yaml").NewError(ERROR, testMessage)
+
+	b, err := json.Marshal(&errs)
+	require.Nil(t, err)
+	require.Equal(t, `{"errors":[],"level":"error","groups":[{"name":"app.yaml","errors":[{"level":"error","message":"test error"}],"level":"error"}]}`, string(b))
+
+	var got Errors
+	require.Nil(t, json.Unmarshal(b, &got))
+	require.True(t, errs.Equal(got))
+
+	// Groups of an unmarshalled Errors are linked to their parent.
+	got.Groups[0].NewError(FATAL, testMessage)
+	require.True(t, got.IsFatal())
+}
+
+func TestGroupAppendCloneEqual(t *testing.T) {
+	SetConfig(DefaultConfig())
+	a := testGroups()
+
+	b := New()
+	b.Group("app.yaml").NewError(FATAL, "cannot read")
+	b.Group("other.yaml").NewError(WARN, testMessage)
+
+	a.Append(b)
+	require.True(t, a.IsFatal())
+	require.Len(t, a.Groups, 2)
+	require.Equal(t, 6, a.Count())
+	require.Equal(t, "cannot read", a.Group("app.yaml").Errors[0].Message)
+
+	c := a.Clone()
+	require.True(t, a.Equal(c))
+	c.Group("app.yaml").Group("server").NewError(ERROR, "new")
+	require.False(t, a.Equal(c))
+	require.Equal(t, 6, a.Count())
+
+	d := a.Clone()
+	d.Groups[0].Name = "renamed.yaml"
+	require.False(t, a.Equal(d))
+}
+
+func TestGroupCopyRelinks(t *testing.T) {
+	SetConfig(DefaultConfig())
+	build := func() Errors {
+		errs := New()
+		errs.Group("app.yaml").NewError(WARN, testMessage)
+		return errs
+	}
+
+	errs := build()
+	errs.Group("app.yaml").NewError(FATAL, testMessage)
+	require.True(t, errs.IsFatal())
+}
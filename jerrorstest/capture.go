@@ -0,0 +1,119 @@
+package jerrorstest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chadeldridge/jerrors"
+)
+
+// captures maps each test to its active Logs so AssertLogged can find them.
+var (
+	capturesMu sync.Mutex
+	captures   = map[testing.TB]*Logs{}
+)
+
+// Logs holds the output captured by CaptureLogs.
+type Logs struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write appends p to the captured output.
+func (l *Logs) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.buf.Write(p)
+}
+
+// String returns all captured output.
+func (l *Logs) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.buf.String()
+}
+
+// Reset discards all captured output.
+func (l *Logs) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf.Reset()
+}
+
+// Entries decodes each captured line as a jerrors.Error. Lines that are not Errors are skipped.
+func (l *Logs) Entries() []jerrors.Error {
+	var entries []jerrors.Error
+	s := bufio.NewScanner(strings.NewReader(l.String()))
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		var e jerrors.Error
+		if err := json.Unmarshal(s.Bytes(), &e); err == nil {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// CaptureLogs redirects jerrors log output to the returned Logs until t ends, then restores the
+// previous output. Log output is global, so tests using CaptureLogs must not run in parallel.
+func CaptureLogs(t testing.TB) *Logs {
+	t.Helper()
+
+	l := &Logs{}
+	prev := log.Writer()
+	jerrors.SetLogOutput(l)
+
+	capturesMu.Lock()
+	captures[t] = l
+	capturesMu.Unlock()
+
+	t.Cleanup(func() {
+		jerrors.SetLogOutput(prev)
+
+		capturesMu.Lock()
+		delete(captures, t)
+		capturesMu.Unlock()
+	})
+
+	return l
+}
+
+// AssertLogged fails t unless an Error with level and a Message matching msgRegex was logged
+// since CaptureLogs was called for t. Returns true if one was found.
+func AssertLogged(t testing.TB, level jerrors.Level, msgRegex string) bool {
+	t.Helper()
+
+	capturesMu.Lock()
+	l, ok := captures[t]
+	capturesMu.Unlock()
+	if !ok {
+		t.Fatalf("AssertLogged: CaptureLogs was not called for %s", t.Name())
+		return false
+	}
+
+	re, err := regexp.Compile(msgRegex)
+	if err != nil {
+		t.Fatalf("AssertLogged: invalid regex %q: %s", msgRegex, err)
+		return false
+	}
+
+	entries := l.Entries()
+	for _, e := range entries {
+		if e.Level == level && re.MatchString(e.Message) {
+			return true
+		}
+	}
+
+	t.Errorf("no %s error matching %q was logged. Logged:\n%s", level, msgRegex, l.String())
+	return false
+}
@@ -0,0 +1,132 @@
+package jerrorstest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes AssertGolden rewrite golden files when set to
+// true: JERRORS_UPDATE_GOLDEN=1 go test ./...
+const UpdateEnv = "JERRORS_UPDATE_GOLDEN"
+
+// Update makes AssertGolden rewrite golden files instead of comparing them. Tests can set it from
+// their own flag.
+var Update bool
+
+// updating returns true if golden files should be rewritten: Update is set, the test binary
+// defines a boolean -update flag that is set, or UpdateEnv is true. No flag is registered here so
+// test packages can define their own -update.
+func updating() bool {
+	if Update {
+		return true
+	}
+
+	if f := flag.Lookup("update"); f != nil {
+		if b, err := strconv.ParseBool(f.Value.String()); err == nil && b {
+			return true
+		}
+	}
+
+	b, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return b
+}
+
+// volatile matches JSON fields that change between runs.
+var volatile = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`"time":(\s*)("(?:[^"\\]|\\.)*"|-?\d+)`), `"time":$1"<time>"`},
+	{regexp.MustCompile(`"caller":(\s*)"(?:[^"\\]|\\.)*"`), `"caller":$1"<caller>"`},
+}
+
+// Normalize replaces the time and caller values in JSON output with placeholders so it can be
+// compared between runs.
+func Normalize(b []byte) []byte {
+	for _, v := range volatile {
+		b = v.re.ReplaceAll(b, []byte(v.repl))
+	}
+
+	return b
+}
+
+// GoldenPath returns the path of the golden file for name: testdata/<name>.golden
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// AssertGolden compares the normalized got with the golden file for name. When Update, UpdateEnv or
+// a test binary -update flag is set the golden file is written instead. Returns true if they match.
+func AssertGolden(t testing.TB, name string, got []byte) bool {
+	t.Helper()
+
+	got = Normalize(got)
+	path := GoldenPath(name)
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("AssertGolden: %s", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("AssertGolden: %s", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("AssertGolden: %s (set %s=true to create it)", err, UpdateEnv)
+		return false
+	}
+
+	if d := lineDiff(string(want), string(got)); d != "" {
+		t.Errorf("%s mismatch (-want +got):\n%s", path, d)
+		return false
+	}
+
+	return true
+}
+
+// AssertGoldenJSON marshals v as indented JSON and compares it with the golden file for name.
+func AssertGoldenJSON(t testing.TB, name string, v interface{}) bool {
+	t.Helper()
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("AssertGoldenJSON: %s", err)
+		return false
+	}
+
+	return AssertGolden(t, name, append(b, '\n'))
+}
+
+// lineDiff returns the differing lines of want and got, or "" if they are equal.
+func lineDiff(want, got string) string {
+	if want == got {
+		return ""
+	}
+
+	w := strings.Split(want, "\n")
+	g := strings.Split(got, "\n")
+
+	var b strings.Builder
+	for i := 0; i < len(w) || i < len(g); i++ {
+		switch {
+		case i >= len(w):
+			fmt.Fprintf(&b, "%4d + %s\n", i+1, g[i])
+		case i >= len(g):
+			fmt.Fprintf(&b, "%4d - %s\n", i+1, w[i])
+		case w[i] != g[i]:
+			fmt.Fprintf(&b, "%4d - %s\n%4d + %s\n", i+1, w[i], i+1, g[i])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,153 @@
+// Package jerrorstest provides test helpers for code using jerrors: assertions that compare
+// Errors while ignoring volatile fields, log capture and golden files.
+package jerrorstest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/chadeldridge/jerrors"
+)
+
+// Option changes how Errors are compared.
+type Option func(*options)
+
+type options struct {
+	ignoreTime     bool
+	ignoreMetadata map[string]bool
+}
+
+// IgnoreTime ignores Error.Time when comparing.
+func IgnoreTime() Option {
+	return func(o *options) { o.ignoreTime = true }
+}
+
+// IgnoreCaller ignores the "caller" Metadata added when Config.LogCaller is set.
+func IgnoreCaller() Option { return IgnoreMetadata("caller") }
+
+// IgnoreMetadata ignores the given Metadata keys when comparing.
+func IgnoreMetadata(keys ...string) Option {
+	return func(o *options) {
+		for _, k := range keys {
+			o.ignoreMetadata[k] = true
+		}
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{ignoreMetadata: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// AssertError fails t with a readable diff if got and want differ. Returns true if they match.
+func AssertError(t testing.TB, got, want jerrors.Error, opts ...Option) bool {
+	t.Helper()
+
+	if d := Diff(got, want, opts...); d != "" {
+		t.Errorf("jerrors.Error mismatch (-want +got):\n%s", d)
+		return false
+	}
+
+	return true
+}
+
+// Diff returns a line for each difference between got and want, or "" if they match.
+func Diff(got, want jerrors.Error, opts ...Option) string {
+	o := newOptions(opts)
+	got, want = got.Resolve(), want.Resolve()
+
+	var lines []string
+	add := func(field string, want, got interface{}) {
+		lines = append(lines, fmt.Sprintf("  %s:\n    - %v\n    + %v", field, want, got))
+	}
+
+	if got.Level != want.Level {
+		add("level", want.Level, got.Level)
+	}
+
+	if got.Message != want.Message {
+		add("message", fmt.Sprintf("%q", want.Message), fmt.Sprintf("%q", got.Message))
+	}
+
+	if !got.Field.Equal(want.Field) {
+		add("field", want.Field.Pointer(), got.Field.Pointer())
+	}
+
+	if !got.Position.Equal(want.Position) {
+		add("position", want.Position, got.Position)
+	}
+
+	if got.Retryable != want.Retryable {
+		add("retryable", want.Retryable, got.Retryable)
+	}
+
+	if got.Temporary != want.Temporary {
+		add("temporary", want.Temporary, got.Temporary)
+	}
+
+	if got.RetryAfter != want.RetryAfter {
+		add("retry_after", want.RetryAfter, got.RetryAfter)
+	}
+
+	if !o.ignoreTime && !timeEqual(got, want) {
+		add("time", timeString(want), timeString(got))
+	}
+
+	keys := make(map[string]bool)
+	for k := range got.Metadata {
+		keys[k] = true
+	}
+	for k := range want.Metadata {
+		keys[k] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		if !o.ignoreMetadata[k] {
+			sorted = append(sorted, k)
+		}
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		w, wok := want.Metadata[k]
+		g, gok := got.Metadata[k]
+		if w == g && wok == gok {
+			continue
+		}
+
+		add("metadata["+k+"]", metadataString(w, wok), metadataString(g, gok))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func timeEqual(got, want jerrors.Error) bool {
+	if got.Time == nil || want.Time == nil {
+		return got.Time == want.Time
+	}
+
+	return got.Time.Equal(*want.Time)
+}
+
+func timeString(e jerrors.Error) string {
+	if e.Time == nil {
+		return "<nil>"
+	}
+
+	return e.Time.String()
+}
+
+func metadataString(v string, ok bool) string {
+	if !ok {
+		return "<missing>"
+	}
+
+	return fmt.Sprintf("%q", v)
+}
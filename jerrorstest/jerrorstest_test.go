@@ -0,0 +1,159 @@
+package jerrorstest
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chadeldridge/jerrors"
+	"github.com/stretchr/testify/require"
+)
+
+// update is defined by the test package, as users of jerrorstest do, and is found by updating.
+var update = flag.Bool("update", false, "update golden files")
+
+// fakeT records failures instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errors []string
+	fatals []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.fatals = append(f.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestAssertError(t *testing.T) {
+	jerrors.SetConfig(jerrors.DefaultConfig())
+
+	want := jerrors.NewError(jerrors.ERROR, "test error", "user", "bob")
+	time.Sleep(time.Millisecond)
+	got := jerrors.NewError(jerrors.ERROR, "test error", "user", "bob")
+
+	ft := &fakeT{TB: t}
+	require.False(t, AssertError(ft, got, want))
+	require.Len(t, ft.errors, 1)
+	require.Contains(t, ft.errors[0], "time:")
+
+	require.True(t, AssertError(t, got, want, IgnoreTime()))
+}
+
+func TestAssertErrorDiff(t *testing.T) {
+	jerrors.SetConfig(jerrors.DefaultConfig())
+
+	want := jerrors.NewError(jerrors.ERROR, "test error", "user", "bob", "id", "1")
+	got := want
+	got.Level = jerrors.WARN
+	got.Message = "other error"
+	got.Metadata = map[string]string{"user": "alice", "caller": "main.main{1}", "extra": "x"}
+
+	d := Diff(got, want, IgnoreTime(), IgnoreCaller())
+	require.Equal(t, `  level:
+    - error
+    + warn
+  message:
+    - "test error"
+    + "other error"
+  metadata[extra]:
+    - <missing>
+    + "x"
+  metadata[id]:
+    - "1"
+    + <missing>
+  metadata[user]:
+    - "bob"
+    + "alice"`, d)
+
+	d = Diff(got, want, IgnoreTime(), IgnoreCaller(), IgnoreMetadata("extra", "id", "user"))
+	require.NotContains(t, d, "metadata")
+}
+
+func TestCaptureLogs(t *testing.T) {
+	jerrors.SetConfig(jerrors.DefaultConfig())
+
+	logs := CaptureLogs(t)
+	err := jerrors.NewError(jerrors.WARN, "disk 91% full", "mount", "/var")
+	err.Log()
+
+	entries := logs.Entries()
+	require.Len(t, entries, 1)
+	require.Equal(t, "/var", entries[0].Metadata["mount"])
+
+	require.True(t, AssertLogged(t, jerrors.WARN, `^disk \d+% full$`))
+
+	ft := &fakeT{TB: t}
+	captures[ft] = logs
+	defer delete(captures, ft)
+	require.False(t, AssertLogged(ft, jerrors.ERROR, `disk`))
+	require.Len(t, ft.errors, 1)
+
+	logs.Reset()
+	require.Empty(t, logs.String())
+}
+
+func TestAssertLoggedWithoutCapture(t *testing.T) {
+	ft := &fakeT{TB: t}
+	require.False(t, AssertLogged(ft, jerrors.ERROR, `.*`))
+	require.Len(t, ft.fatals, 1)
+}
+
+func TestNormalize(t *testing.T) {
+	in := `{"time":"2024-07-18T13:09:25.355507403-04:00","level":"error","metadata":{"caller":"a{1}->b{2}"}}
+{"time": 1721322565,"level":"info"}`
+	want := `{"time":"<time>","level":"error","metadata":{"caller":"<caller>"}}
+{"time": "<time>","level":"info"}`
+	require.Equal(t, want, string(Normalize([]byte(in))))
+}
+
+func TestAssertGolden(t *testing.T) {
+	c := jerrors.DefaultConfig()
+	c.LogCaller = true
+	jerrors.SetConfig(c)
+	defer jerrors.SetConfig(jerrors.DefaultConfig())
+
+	errs := jerrors.New()
+	errs.NewError(jerrors.ERROR, "test error", "user", "bob")
+	errs.Field("user.email", jerrors.WARN, "required")
+	require.True(t, AssertGoldenJSON(t, "errors", errs))
+	if updating() {
+		return
+	}
+
+	ft := &fakeT{TB: t}
+	require.False(t, AssertGolden(ft, "errors", []byte("{}\n")))
+	require.Len(t, ft.errors, 1)
+	require.Contains(t, ft.errors[0], "   1 - {")
+
+	ft = &fakeT{TB: t}
+	require.False(t, AssertGolden(ft, "missing", []byte("{}\n")))
+	require.Len(t, ft.fatals, 1)
+}
+
+func TestUpdating(t *testing.T) {
+	if *update {
+		t.Skip("run with -update")
+	}
+
+	t.Setenv(UpdateEnv, "")
+	require.False(t, updating())
+
+	t.Setenv(UpdateEnv, "true")
+	require.True(t, updating())
+
+	t.Setenv(UpdateEnv, "")
+	Update = true
+	defer func() { Update = false }()
+	require.True(t, updating())
+}
+
+func TestLineDiff(t *testing.T) {
+	require.Equal(t, "", lineDiff("a\nb", "a\nb"))
+	require.Equal(t, "   2 - b\n   2 + c\n   3 + d\n", lineDiff("a\nb", "a\nc\nd"))
+}
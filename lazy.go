@@ -0,0 +1,118 @@
+package jerrors
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Lazy is a Metadata value computed only when the Error is written, such as when it is logged,
+// converted to a string or encoded. Use it for expensive values like dumps of request bodies
+// that are only needed if the Error is logged. The function is called each time the Error is
+// written and its result is converted like any other arg.
+// Example:
+// err := jerrors.NewError(jerrors.DEBUG, "request", "body", jerrors.Lazy(func() any { return dump(req) }))
+type Lazy func() any
+
+// lazyMetadata is a Metadata key whose Lazy value has not been computed.
+type lazyMetadata struct {
+	key   string
+	value Lazy
+}
+
+// Enabled returns true if an Error with level would be logged by the package calling Enabled.
+// Use it to skip building Errors that would be filtered.
+// Example:
+//
+//	if jerrors.Enabled(jerrors.DEBUG) {
+//		jerrors.NewError(jerrors.DEBUG, "state", "dump", dump()).Log()
+//	}
+func Enabled(level Level) bool {
+	min, _ := minLevel(1)
+	return level >= min
+}
+
+// Logf logs an Error with level and a message formatted with fmt.Sprintf. The message is not
+// formatted if level would not be logged. Lazy args are computed before formatting.
+func Logf(level Level, format string, args ...interface{}) {
+	if min, _ := minLevel(1); level < min {
+		return
+	}
+
+	e := newError(level, fmt.Sprintf(format, lazyArgs(args)...))
+	countCreated(e)
+	if sampled(e) {
+		e.log(2)
+		countLogged(e)
+	}
+}
+
+// Debugf logs a DEBUG Error with a message formatted with fmt.Sprintf. It does nothing if DEBUG
+// would not be logged. Lazy args are computed before formatting.
+func Debugf(format string, args ...interface{}) {
+	if min, _ := minLevel(1); DEBUG < min {
+		return
+	}
+
+	e := newError(DEBUG, fmt.Sprintf(format, lazyArgs(args)...))
+	countCreated(e)
+	if sampled(e) {
+		e.log(2)
+		countLogged(e)
+	}
+}
+
+// lazyArgs returns args with Lazy values computed. args is copied before it is changed.
+func lazyArgs(args []interface{}) []interface{} {
+	copied := false
+	for i, arg := range args {
+		l, ok := arg.(Lazy)
+		if !ok {
+			continue
+		}
+
+		if !copied {
+			args = slices.Clone(args)
+			copied = true
+		}
+		args[i] = l()
+	}
+
+	return args
+}
+
+// Resolve returns a copy of the Error with its Lazy Metadata values computed and added to
+// Metadata. The Error is returned unchanged if it has no Lazy values.
+func (e Error) Resolve() Error {
+	if len(e.lazy) == 0 {
+		return e
+	}
+
+	m := maps.Clone(e.Metadata)
+	if m == nil {
+		m = make(map[string]string, len(e.lazy))
+	}
+
+	for _, l := range e.lazy {
+		m[l.key] = argString(l.value())
+	}
+
+	e.Metadata = m
+	e.lazy = nil
+	return e
+}
+
+// lazyIndex returns the index of key in lazy, or -1 if key has no Lazy value.
+func (e Error) lazyIndex(key string) int {
+	return slices.IndexFunc(e.lazy, func(l lazyMetadata) bool { return l.key == key })
+}
+
+// dropLazy removes the Lazy value for key, if there is one.
+func (e *Error) dropLazy(key string) {
+	i := e.lazyIndex(key)
+	if i < 0 {
+		return
+	}
+
+	e.lazy = slices.Delete(slices.Clone(e.lazy), i, i+1)
+}
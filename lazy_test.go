@@ -0,0 +1,112 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabled(t *testing.T) {
+	c := DefaultConfig()
+	c.LoggingLevel = WARN
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	require.False(t, Enabled(DEBUG))
+	require.False(t, Enabled(INFO))
+	require.True(t, Enabled(WARN))
+	require.True(t, Enabled(FATAL))
+
+	require.Nil(t, SetPackageLevels("github.com/chadeldridge/jerrors=debug"))
+	defer SetPackageLevels("")
+	require.True(t, Enabled(DEBUG))
+}
+
+func TestLazy(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	calls := 0
+	dump := Lazy(func() any {
+		calls++
+		return 42
+	})
+
+	e := NewError(DEBUG, testMessage, "dump", dump, mdUserKey, mdUserVal)
+	require.Zero(t, calls)
+	_, ok := e.Metadata["dump"]
+	require.False(t, ok)
+
+	// Filtered, so nothing is written.
+	e.Log()
+	Log(DEBUG, testMessage, "dump", dump)
+	require.Zero(t, calls)
+
+	j, err := json.Marshal(e)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Contains(t, string(j), `"metadata":{"dump":"42","user":"test1"}`)
+
+	r := e.Resolve()
+	require.Equal(t, 2, calls)
+	require.Equal(t, "42", r.Metadata["dump"])
+	require.Equal(t, mdUserVal, r.Metadata[mdUserKey])
+	_, ok = e.Metadata["dump"]
+	require.False(t, ok, "Resolve changed the original")
+
+	require.True(t, e.Equal(NewError(DEBUG, testMessage, "dump", "42", mdUserKey, mdUserVal)))
+
+	var got Error
+	require.NoError(t, json.Unmarshal(j, &got))
+	require.True(t, r.Equal(got))
+}
+
+func TestLazyReplaced(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	e := NewError(ERROR, testMessage, "k", Lazy(func() any { return "lazy" }))
+	c := e.With("k", "plain")
+	require.Equal(t, "plain", c.Resolve().Metadata["k"])
+	require.Equal(t, "lazy", e.Resolve().Metadata["k"])
+
+	c = c.With("k", Lazy(func() any { return "again" }))
+	require.Equal(t, "again", c.Resolve().Metadata["k"])
+	_, ok := c.Metadata["k"]
+	require.False(t, ok)
+	require.Equal(t, "lazy", e.Resolve().Metadata["k"])
+}
+
+func TestLogf(t *testing.T) {
+	c := DefaultConfig()
+	c.LogTime = false
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	calls := 0
+	dump := Lazy(func() any {
+		calls++
+		return "body"
+	})
+
+	require.Empty(t, logLine(t, func() { Debugf("request %v", dump) }))
+	require.Empty(t, logLine(t, func() { Logf(DEBUG, "request %v", dump) }))
+	require.Zero(t, calls)
+
+	require.Equal(t, `{"level":"info","message":"request 7 body"}`+"\n",
+		logLine(t, func() { Logf(INFO, "request %d %v", 7, dump) }))
+	require.Equal(t, 1, calls)
+
+	c.LoggingLevel = DEBUG
+	SetConfig(c)
+	require.Equal(t, `{"level":"debug","message":"request a"}`+"\n",
+		logLine(t, func() { Debugf("request %s", "a") }))
+}
+
+func TestDebugfAllocs(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		Debugf("request %d", 1)
+	})
+	require.Zero(t, allocs)
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package jerrors
+
+// HandleLevelSignals does nothing on platforms without SIGUSR1 and SIGUSR2.
+func HandleLevelSignals() (stop func()) { return func() {} }
@@ -0,0 +1,51 @@
+//go:build unix
+
+package jerrors
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleLevelSignals lets the logging level be changed on a live process. SIGUSR1 toggles
+// between DEBUG and the level in use before the toggle, SIGUSR2 restores the level in use when
+// HandleLevelSignals was called. Call the returned function to stop handling the signals.
+func HandleLevelSignals() (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	original := loggingLevel.Level()
+	go func() {
+		previous := original
+		for {
+			select {
+			case sig := <-sigs:
+				if sig == syscall.SIGUSR2 {
+					loggingLevel.SetLevel(original)
+					previous = original
+					continue
+				}
+
+				if cur := loggingLevel.Level(); cur != DEBUG {
+					previous = cur
+					loggingLevel.SetLevel(DEBUG)
+				} else {
+					loggingLevel.SetLevel(previous)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package jerrors
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func waitForLevel(t *testing.T, want Level) {
+	require.Eventually(t, func() bool { return LoggingLevel().Level() == want }, time.Second, time.Millisecond)
+}
+
+func TestHandleLevelSignals(t *testing.T) {
+	SetConfig(DefaultConfig())
+	defer SetConfig(DefaultConfig())
+
+	stop := HandleLevelSignals()
+	defer stop()
+
+	require.Nil(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	waitForLevel(t, DEBUG)
+
+	require.Nil(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	waitForLevel(t, INFO)
+
+	SetLogLevel(ERROR)
+	require.Nil(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	waitForLevel(t, DEBUG)
+
+	require.Nil(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	waitForLevel(t, INFO)
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"time"
 )
 
 func init() {
@@ -22,3 +23,92 @@ func SetLogOutput(w io.Writer) {
 	}
 	log.SetOutput(w)
 }
+
+// inlineMetadata is the most pairs of args Log writes without making an Error.
+const inlineMetadata = 8
+
+// metadataArg is a pair of args waiting to be written by Log.
+type metadataArg struct {
+	key   string
+	value interface{}
+}
+
+// Log creates an Error and logs it, writing the same line as NewError(level, msg, args...).Log().
+// It is the fast path for hot loops: nothing is allocated if level is below the Logging Level,
+// and for up to 8 pairs of args the JSON is written straight from them into a pooled buffer
+// without making the Error or its Metadata map. Log falls back to NewError when more args are
+// given, Config.DuplicateKeys is DuplicateKeepBoth or Config.LogCaller, Config.LogFingerprint,
+// a Sampler or Metrics need the Error.
+//
+// The caller converts args to interface values, which allocates for non-constant values other
+// than small integers even if nothing is logged.
+// Example:
+// jerrors.Log(jerrors.DEBUG, "cache miss", "key", "user")
+func Log(level Level, msg string, args ...interface{}) {
+	if min, _ := minLevel(1); level < min {
+		return
+	}
+
+	c := config.Load()
+	if len(args) > 2*inlineMetadata+1 || c.DuplicateKeys == DuplicateKeepBoth || c.LogCaller ||
+		c.LogFingerprint || GetSampler() != nil || GetMetrics() != nil {
+		e := newError(level, msg, args...)
+		countCreated(e)
+		if sampled(e) {
+			e.log(2)
+			countLogged(e)
+		}
+		return
+	}
+
+	// Keep the pairs in order, replacing the value of a repeated key like DuplicateLastWins.
+	var pairs [inlineMetadata]metadataArg
+	n := 0
+	for i := 0; i+1 < len(args); i += 2 {
+		p := metadataArg{key: argString(args[i]), value: args[i+1]}
+		j := 0
+		for j < n && pairs[j].key != p.key {
+			j++
+		}
+
+		pairs[j] = p
+		if j == n {
+			n++
+		}
+	}
+
+	e := Error{Level: level, Message: msg}
+	var t time.Time
+	if c.LogTime {
+		t = now()
+		if c.UTC {
+			t = t.UTC()
+		}
+		e.Time = &t
+	}
+
+	b := getBuffer()
+	defer putBuffer(b)
+
+	*b = e.appendLog(*b)
+	if n > 0 {
+		// Replace the closing brace with the metadata, the last key written for this Error.
+		*b = (*b)[:len(*b)-1]
+		if len(*b) > 1 {
+			*b = append(*b, ',')
+		}
+
+		*b = append(*b, `"metadata":{`...)
+		for i, p := range pairs[:n] {
+			if i > 0 {
+				*b = append(*b, ',')
+			}
+			*b = appendJSONString(*b, p.key)
+			*b = append(*b, ':')
+			*b = appendArgJSON(*b, p.value)
+		}
+		*b = append(*b, '}', '}')
+	}
+
+	output(2, *b)
+}
@@ -8,6 +8,11 @@ import (
 )
 
 func TestLogsSetLogOutput(t *testing.T) {
+	c := DefaultConfig()
+	c.LoggingLevel = DEBUG
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
 	var buf bytes.Buffer
 	SetLogOutput(&buf)
 
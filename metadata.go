@@ -0,0 +1,188 @@
+package jerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// Duplicate key policies for Config.DuplicateKeys, used when a Metadata key that is already set
+// is added again.
+const (
+	// DuplicateLastWins replaces the value and keeps the key in its first position. The default.
+	DuplicateLastWins = "last"
+	// DuplicateKeepBoth keeps the first value and adds the new one under the key with a "_2",
+	// "_3"... suffix.
+	DuplicateKeepBoth = "both"
+	// DuplicateError keeps the first value and makes SetMetadata return ErrDuplicateKey. It only
+	// applies to SetMetadata, which can return the error; NewError, AddMetadata and With replace
+	// the value like DuplicateLastWins.
+	DuplicateError = "error"
+)
+
+// ErrDuplicateKey is returned by SetMetadata for a key that is already set when
+// Config.DuplicateKeys is DuplicateError.
+var ErrDuplicateKey = errors.New("jerrors: duplicate metadata key")
+
+// MetadataKeys returns the Metadata keys in the order they were added, including keys with Lazy
+// values. Keys written to the Metadata map directly come last, sorted.
+func (e Error) MetadataKeys() []string {
+	keys := make([]string, 0, len(e.Metadata)+len(e.lazy))
+	for _, k := range e.keys {
+		if e.hasMetadata(k) && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	n := len(keys)
+	for k := range e.Metadata {
+		if !slices.Contains(keys[:n], k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[n:])
+
+	return keys
+}
+
+// GetMetadata returns the value of key and whether it is set. Lazy values are computed.
+func (e Error) GetMetadata(key string) (string, bool) {
+	if v, ok := e.Metadata[key]; ok {
+		return v, true
+	}
+
+	if i := e.lazyIndex(key); i >= 0 {
+		return argString(e.lazy[i].value()), true
+	}
+
+	return "", false
+}
+
+// SetMetadata sets key to value, converted like the args of NewError. value may be Lazy. A key
+// that is already set follows Config.DuplicateKeys, returning ErrDuplicateKey for DuplicateError.
+// New keys are added after the existing ones. The Metadata is copied before it is changed, so
+// copies of the Error sharing it are not affected.
+func (e *Error) SetMetadata(key string, value interface{}) error {
+	if config.Load().DuplicateKeys == DuplicateError && e.hasMetadata(key) {
+		return fmt.Errorf("%w: %q", ErrDuplicateKey, key)
+	}
+
+	e.ownMetadata()
+	e.putMetadata(key, value)
+	return nil
+}
+
+// DeleteMetadata removes key from the Metadata. The Metadata is copied before it is changed, so
+// copies of the Error sharing it are not affected.
+func (e *Error) DeleteMetadata(key string) {
+	if !e.hasMetadata(key) {
+		return
+	}
+
+	e.ownMetadata()
+	delete(e.Metadata, key)
+	e.dropLazy(key)
+
+	if i := slices.Index(e.keys, key); i >= 0 {
+		e.keys = slices.Delete(slices.Clone(e.keys), i, i+1)
+	}
+}
+
+// ownMetadata replaces the Metadata map with a copy, so changes to it do not reach copies of the
+// Error sharing the original. keys and lazy are never changed in place, so they need no copy.
+func (e *Error) ownMetadata() {
+	e.Metadata = maps.Clone(e.Metadata)
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]string)
+	}
+}
+
+// hasMetadata returns true if key is set in the Metadata map or has a Lazy value.
+func (e Error) hasMetadata(key string) bool {
+	_, ok := e.Metadata[key]
+	return ok || e.lazyIndex(key) >= 0
+}
+
+// putMetadata adds a user key with value, moving it to a free key for DuplicateKeepBoth and
+// replacing the value otherwise.
+func (e *Error) putMetadata(key string, value interface{}) {
+	if config.Load().DuplicateKeys == DuplicateKeepBoth && e.hasMetadata(key) {
+		key = e.freeKey(key)
+	}
+
+	e.setMetadata(key, value)
+}
+
+// setMetadata sets key to value, replacing any value and keeping the key's position. It ignores
+// Config.DuplicateKeys, so it is used for the keys the package writes itself. The Metadata map
+// must not be shared with other Errors.
+func (e *Error) setMetadata(key string, value interface{}) {
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]string)
+	}
+
+	if !slices.Contains(e.keys, key) {
+		// Clip so copies of the Error sharing keys are not changed.
+		e.keys = append(slices.Clip(e.keys), key)
+	}
+
+	e.dropLazy(key)
+	if l, ok := value.(Lazy); ok {
+		delete(e.Metadata, key)
+		e.lazy = append(slices.Clip(e.lazy), lazyMetadata{key: key, value: l})
+		return
+	}
+
+	e.Metadata[key] = argString(value)
+}
+
+// freeKey returns key with the first "_2", "_3"... suffix that is not set.
+func (e Error) freeKey(key string) string {
+	for n := 2; ; n++ {
+		k := key + "_" + strconv.Itoa(n)
+		if !e.hasMetadata(k) {
+			return k
+		}
+	}
+}
+
+// decodeMetadataJSON decodes a JSON object of strings into Metadata, returning its keys in order.
+func decodeMetadataJSON(b []byte) (map[string]string, []string, error) {
+	if string(b) == "null" {
+		return nil, nil, nil
+	}
+
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	if _, err := d.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, 0, len(m))
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var v json.RawMessage
+		if err := d.Decode(&v); err != nil {
+			return nil, nil, err
+		}
+
+		if k := t.(string); !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	return m, keys, nil
+}
@@ -0,0 +1,124 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataOrder(t *testing.T) {
+	c := DefaultConfig()
+	c.LogTime = false
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	e := NewError(ERROR, testMessage, "user", "test1", "b", 2, "a", Lazy(func() any { return 1 }))
+	require.Equal(t, []string{"user", "b", "a"}, e.MetadataKeys())
+	require.Equal(t, `{"level":"error","message":"test error","metadata":{"user":"test1","b":"2","a":"1"}}`, e.String())
+
+	// Keys written to the map directly come last, sorted.
+	e.Metadata["z"] = "26"
+	e.Metadata["y"] = "25"
+	require.Equal(t, []string{"user", "b", "a", "y", "z"}, e.MetadataKeys())
+	require.Contains(t, e.String(), `"metadata":{"user":"test1","b":"2","a":"1","y":"25","z":"26"}`)
+
+	var got Error
+	require.NoError(t, json.Unmarshal([]byte(e.String()), &got))
+	require.Equal(t, []string{"user", "b", "a", "y", "z"}, got.MetadataKeys())
+	require.Equal(t, e.String(), got.String())
+
+	for _, codec := range binaryCodecs {
+		b, err := codec.marshalError(e)
+		require.NoError(t, err)
+
+		var got Error
+		require.NoError(t, codec.unmarshalError(&got, b))
+		require.Equal(t, []string{"user", "b", "a", "y", "z"}, got.MetadataKeys(), codec.name)
+	}
+}
+
+func TestMetadataGetSetDelete(t *testing.T) {
+	SetConfig(DefaultConfig())
+
+	e := NewError(ERROR, testMessage, "user", "test1", "lazy", Lazy(func() any { return "value" }))
+	v, ok := e.GetMetadata("lazy")
+	require.True(t, ok)
+	require.Equal(t, "value", v)
+	_, ok = e.GetMetadata("missing")
+	require.False(t, ok)
+
+	c := e
+	require.NoError(t, c.SetMetadata("user", "test2"))
+	require.NoError(t, c.SetMetadata("new", 3))
+	require.Equal(t, []string{"user", "lazy", "new"}, c.MetadataKeys())
+	require.Equal(t, "test2", c.Metadata["user"])
+	require.Equal(t, "3", c.Metadata["new"])
+
+	c.DeleteMetadata("user")
+	c.DeleteMetadata("lazy")
+	c.DeleteMetadata("missing")
+	require.Equal(t, []string{"new"}, c.MetadataKeys())
+	require.Equal(t, map[string]string{"new": "3"}, c.Resolve().Metadata)
+
+	// The original is not changed.
+	require.Equal(t, []string{"user", "lazy"}, e.MetadataKeys())
+	require.Equal(t, "test1", e.Metadata["user"])
+
+	require.NoError(t, c.SetMetadata("user", "test3"))
+	require.Equal(t, []string{"new", "user"}, c.MetadataKeys())
+}
+
+func TestMetadataDuplicateKeys(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+
+	tests := []struct {
+		policy string
+		want   string
+		keys   []string
+	}{
+		{"", `{"id":"3","user":"test1"}`, []string{"id", "user"}},
+		{DuplicateLastWins, `{"id":"3","user":"test1"}`, []string{"id", "user"}},
+		{DuplicateKeepBoth, `{"id":"1","user":"test1","id_2":"2","id_3":"3"}`, []string{"id", "user", "id_2", "id_3"}},
+		{DuplicateError, `{"id":"3","user":"test1"}`, []string{"id", "user"}},
+	}
+
+	for _, tt := range tests {
+		c := DefaultConfig()
+		c.LogTime = false
+		c.DuplicateKeys = tt.policy
+		SetConfig(c)
+
+		e := NewError(ERROR, testMessage, "id", 1, "user", "test1", "id", 2)
+		e.AddMetadata("id", 3)
+		require.Equal(t, tt.keys, e.MetadataKeys(), tt.policy)
+		require.True(t, strings.HasSuffix(e.String(), `"metadata":`+tt.want+"}"), "%s: %s", tt.policy, e)
+
+		err := e.SetMetadata("id", 4)
+		if tt.policy == DuplicateError {
+			require.True(t, errors.Is(err, ErrDuplicateKey))
+			require.Equal(t, "3", e.Metadata["id"])
+		} else {
+			require.NoError(t, err)
+		}
+
+		want := logLine(t, func() {
+			e := NewError(ERROR, testMessage, "id", 1, "user", "test1", "id", 2)
+			e.Log()
+		})
+		require.Equal(t, want, logLine(t, func() { Log(ERROR, testMessage, "id", 1, "user", "test1", "id", 2) }))
+
+		// Keys the package writes itself are replaced whatever the policy.
+		errs := New()
+		errs.Group("db").NewError(ERROR, testMessage, GroupKey, "old")
+		flat := errs.Flatten()
+		require.Equal(t, "db", flat[0].Metadata[GroupKey], tt.policy)
+		require.NotContains(t, flat[0].Metadata, GroupKey+"_2", tt.policy)
+	}
+
+	c := DefaultConfig()
+	c.DuplicateKeys = "first"
+	require.Error(t, c.Validate())
+}
@@ -0,0 +1,249 @@
+package jerrors
+
+import (
+	"bufio"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// metrics is the active Metrics updated by NewError and Log. nil disables metrics.
+var (
+	metrics   *Metrics
+	metricsMu sync.RWMutex
+)
+
+// SetMetrics sets the Metrics counting created and logged Errors. Pass nil to disable metrics.
+func SetMetrics(m *Metrics) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	metrics = m
+}
+
+// GetMetrics returns the active Metrics or nil if metrics are disabled.
+func GetMetrics() *Metrics {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+
+	return metrics
+}
+
+// MetricsConfig holds the options for Metrics.
+type MetricsConfig struct {
+	// Namespace prefixes the metric names. Defaults to "jerrors".
+	Namespace string
+	// Labels are the Metadata keys added as labels besides level and code. Keep this list short
+	// and only use keys with few distinct values, since every combination is its own series. Keys
+	// whose label name is level, code or the name of an earlier key are skipped.
+	Labels []string
+}
+
+// DefaultMetricsConfig returns a config labeling the counters by level and code only.
+func DefaultMetricsConfig() MetricsConfig {
+	return MetricsConfig{Namespace: "jerrors", Labels: []string{}}
+}
+
+// Metrics counts created and logged Errors by level, code and the configured Metadata labels. It
+// serves the counts in the Prometheus text exposition format and can be published with expvar.
+// Example:
+// m := jerrors.NewMetrics(jerrors.DefaultMetricsConfig())
+// jerrors.SetMetrics(m)
+// http.Handle("/metrics", m)
+type Metrics struct {
+	config MetricsConfig
+	labels []string
+
+	mu      sync.Mutex
+	created map[string]*series
+	logged  map[string]*series
+}
+
+// series is a single counter and its label values.
+type series struct {
+	values []string
+	count  uint64
+}
+
+// NewMetrics creates a new Metrics. An empty Namespace uses "jerrors". Labels that would repeat a
+// label name, such as "level", "db.table" after "db_table" or a key listed twice, are skipped.
+func NewMetrics(c MetricsConfig) *Metrics {
+	if c.Namespace == "" {
+		c.Namespace = DefaultMetricsConfig().Namespace
+	}
+
+	labels := []string{"level", "code"}
+	keys := make([]string, 0, len(c.Labels))
+	for _, k := range c.Labels {
+		name := labelName(k)
+		if slices.Contains(labels, name) {
+			continue
+		}
+
+		labels = append(labels, name)
+		keys = append(keys, k)
+	}
+	c.Labels = keys
+
+	return &Metrics{
+		config:  c,
+		labels:  labels,
+		created: make(map[string]*series),
+		logged:  make(map[string]*series),
+	}
+}
+
+// IncCreated counts e as created.
+func (m *Metrics) IncCreated(e Error) { m.inc(false, e) }
+
+// IncLogged counts e as logged.
+func (m *Metrics) IncLogged(e Error) { m.inc(true, e) }
+
+// Reset sets every counter back to zero.
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.created = make(map[string]*series)
+	m.logged = make(map[string]*series)
+}
+
+// Counts returns the "created" and "logged" counters keyed by their labels, such as
+// `level="error",code="not_found"`.
+func (m *Metrics) Counts() map[string]map[string]uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return map[string]map[string]uint64{
+		"created": m.counts(m.created),
+		"logged":  m.counts(m.logged),
+	}
+}
+
+// WritePrometheus writes the counters to w in the Prometheus text exposition format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	m.writeCounter(bw, "created", "Number of Errors created.", m.created)
+	m.writeCounter(bw, "logged", "Number of Errors logged.", m.logged)
+	return bw.Flush()
+}
+
+// ServeHTTP serves the counters in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+// Publish exports Counts with expvar under name, so they are served on /debug/vars. Like
+// expvar.Publish, it panics if name is already published.
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return m.Counts() }))
+}
+
+// String returns Counts as JSON so Metrics is itself an expvar.Var.
+func (m *Metrics) String() string {
+	b, _ := json.Marshal(m.Counts())
+	return string(b)
+}
+
+func (m *Metrics) inc(logged bool, e Error) {
+	values := make([]string, len(m.labels))
+	values[0] = e.Level.String()
+	values[1] = e.Code()
+	for i, k := range m.config.Labels {
+		values[i+2], _ = e.GetMetadata(k)
+	}
+	key := strings.Join(values, "\x00")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set := m.created
+	if logged {
+		set = m.logged
+	}
+
+	s, ok := set[key]
+	if !ok {
+		s = &series{values: values}
+		set[key] = s
+	}
+	s.count++
+}
+
+func (m *Metrics) counts(set map[string]*series) map[string]uint64 {
+	c := make(map[string]uint64, len(set))
+	for _, s := range set {
+		c[m.labelString(s.values)] += s.count
+	}
+
+	return c
+}
+
+func (m *Metrics) writeCounter(w io.Writer, name, help string, set map[string]*series) {
+	name = m.config.Namespace + "_errors_" + name + "_total"
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := set[k]
+		fmt.Fprintf(w, "%s{%s} %d\n", name, m.labelString(s.values), s.count)
+	}
+}
+
+func (m *Metrics) labelString(values []string) string {
+	pairs := make([]string, len(values))
+	for i, v := range values {
+		pairs[i] = m.labels[i] + `="` + labelEscaper.Replace(v) + `"`
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// labelEscaper escapes label values as required by the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelName makes s a valid Prometheus label name by replacing invalid characters with '_'.
+func labelName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+		if !valid {
+			b[i] = '_'
+		}
+	}
+
+	if len(b) == 0 {
+		return "_"
+	}
+
+	return string(b)
+}
+
+// countCreated counts e with the active Metrics.
+func countCreated(e Error) {
+	if m := GetMetrics(); m != nil {
+		m.IncCreated(e)
+	}
+}
+
+// countLogged counts e with the active Metrics.
+func countLogged(e Error) {
+	if m := GetMetrics(); m != nil {
+		m.IncLogged(e)
+	}
+}
@@ -0,0 +1,120 @@
+package jerrors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setTestMetrics(t *testing.T, c MetricsConfig) *Metrics {
+	m := NewMetrics(c)
+	SetMetrics(m)
+	t.Cleanup(func() { SetMetrics(nil) })
+	return m
+}
+
+func TestMetricsCreatedLogged(t *testing.T) {
+	SetConfig(DefaultConfig())
+	m := setTestMetrics(t, DefaultMetricsConfig())
+
+	SetLogOutput(new(bytes.Buffer))
+	defer SetLogOutput(nil)
+
+	e := NewError(ERROR, testMessage, CodeKey, Lazy(func() any { return "not_found" }))
+	_ = NewError(DEBUG, testMessage)
+	e.Log()
+
+	var errs Errors
+	errs.NewError(WARN, testMessage)
+	errs.Log()
+
+	counts := m.Counts()
+	require.Equal(t, map[string]uint64{
+		`level="error",code="not_found"`: 1,
+		`level="debug",code=""`:          1,
+		`level="warn",code=""`:           1,
+	}, counts["created"])
+	require.Equal(t, map[string]uint64{
+		`level="error",code="not_found"`: 1,
+		`level="warn",code=""`:           1,
+	}, counts["logged"])
+
+	m.Reset()
+	require.Empty(t, m.Counts()["created"])
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	SetConfig(DefaultConfig())
+	SetMetrics(nil)
+	require.Nil(t, GetMetrics())
+	require.NotPanics(t, func() { _ = NewError(ERROR, testMessage) })
+}
+
+func TestMetricsPrometheus(t *testing.T) {
+	m := NewMetrics(MetricsConfig{Namespace: "app", Labels: []string{"db.table"}})
+	m.IncCreated(Error{Level: ERROR, Metadata: map[string]string{"db.table": `us"ers`}})
+	m.IncCreated(Error{Level: ERROR, Metadata: map[string]string{"db.table": `us"ers`}})
+	m.IncLogged(Error{Level: WARN, Metadata: map[string]string{CodeKey: "slow"}})
+
+	want := `# HELP app_errors_created_total Number of Errors created.
+# TYPE app_errors_created_total counter
+app_errors_created_total{level="error",code="",db_table="us\"ers"} 2
+# HELP app_errors_logged_total Number of Errors logged.
+# TYPE app_errors_logged_total counter
+app_errors_logged_total{level="warn",code="slow",db_table=""} 1
+`
+
+	var b bytes.Buffer
+	require.Nil(t, m.WritePrometheus(&b))
+	require.Equal(t, want, b.String())
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Equal(t, want, rec.Body.String())
+}
+
+func TestMetricsExpvar(t *testing.T) {
+	m := NewMetrics(DefaultMetricsConfig())
+	m.IncCreated(Error{Level: INFO})
+	m.Publish("jerrors_test_metrics")
+
+	var got map[string]map[string]uint64
+	require.Nil(t, json.Unmarshal([]byte(expvar.Get("jerrors_test_metrics").String()), &got))
+	require.Equal(t, uint64(1), got["created"][`level="info",code=""`])
+	require.Equal(t, m.String(), expvar.Get("jerrors_test_metrics").String())
+}
+
+func TestMetricsFrom(t *testing.T) {
+	SetConfig(DefaultConfig())
+	m := setTestMetrics(t, DefaultMetricsConfig())
+
+	e := From(context.Canceled)
+	require.Equal(t, WARN, e.Level)
+	require.Equal(t, map[string]uint64{`level="warn",code="canceled"`: 1}, m.Counts()["created"])
+}
+
+func TestMetricsLabelsSkipped(t *testing.T) {
+	SetConfig(DefaultConfig())
+	c := DefaultMetricsConfig()
+	c.Labels = []string{"table", "level", "code", "db.table", "db_table", "table"}
+	m := setTestMetrics(t, c)
+
+	_ = NewError(ERROR, testMessage, "table", "users", "db.table", "orders", "db_table", "other")
+	require.Equal(t, map[string]uint64{
+		`level="error",code="",table="users",db_table="orders"`: 1,
+	}, m.Counts()["created"])
+}
+
+func TestMetricsLabelName(t *testing.T) {
+	require.Equal(t, "db_table", labelName("db.table"))
+	require.Equal(t, "_st", labelName("1st"))
+	require.Equal(t, "_", labelName(""))
+}
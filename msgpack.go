@@ -0,0 +1,344 @@
+package jerrors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// msgpackTimestamp is the MessagePack extension type of timestamps.
+const msgpackTimestamp = 0xff // -1
+
+// MarshalMsgpack encodes the Error as MessagePack. Time is written with the timestamp extension
+// type, so nanoseconds are kept.
+func (e Error) MarshalMsgpack() ([]byte, error) {
+	w := &msgpackEncoder{}
+	encodeError(w, e)
+	return w.b, nil
+}
+
+// UnmarshalMsgpack decodes an Error written by MarshalMsgpack.
+func (e *Error) UnmarshalMsgpack(b []byte) error {
+	return unmarshalBinary(&msgpackDecoder{binaryReader{b}}, func(r binaryDecoder) error { return decodeError(r, e) })
+}
+
+// MarshalMsgpack encodes the Errors, its groups and all of their Errors as MessagePack.
+func (e *Errors) MarshalMsgpack() ([]byte, error) {
+	w := &msgpackEncoder{}
+	encodeErrors(w, e)
+	return w.b, nil
+}
+
+// UnmarshalMsgpack decodes an Errors written by MarshalMsgpack, linking each group to its parent.
+func (e *Errors) UnmarshalMsgpack(b []byte) error {
+	return unmarshalBinary(&msgpackDecoder{binaryReader{b}}, func(r binaryDecoder) error { return decodeErrors(r, e, 0) })
+}
+
+type msgpackEncoder struct {
+	b []byte
+}
+
+// head writes a fix type holding n if it fits in bits, or the 16 or 32 bit type.
+func (w *msgpackEncoder) head(fix byte, bits uint, t16, t32 byte, n int) {
+	switch {
+	case n < 1<<bits:
+		w.b = append(w.b, fix|byte(n))
+	case n <= math.MaxUint16:
+		w.b = binary.BigEndian.AppendUint16(append(w.b, t16), uint16(n))
+	default:
+		w.b = binary.BigEndian.AppendUint32(append(w.b, t32), uint32(n))
+	}
+}
+
+func (w *msgpackEncoder) writeMap(n int)   { w.head(0x80, 4, 0xde, 0xdf, n) }
+func (w *msgpackEncoder) writeArray(n int) { w.head(0x90, 4, 0xdc, 0xdd, n) }
+
+func (w *msgpackEncoder) writeUint(v uint64) {
+	switch {
+	case v < 0x80:
+		w.b = append(w.b, byte(v))
+	case v <= math.MaxUint8:
+		w.b = append(w.b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		w.b = binary.BigEndian.AppendUint16(append(w.b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		w.b = binary.BigEndian.AppendUint32(append(w.b, 0xce), uint32(v))
+	default:
+		w.b = binary.BigEndian.AppendUint64(append(w.b, 0xcf), v)
+	}
+}
+
+func (w *msgpackEncoder) writeInt(v int64) {
+	switch {
+	case v >= 0:
+		w.writeUint(uint64(v))
+	case v >= -32:
+		w.b = append(w.b, byte(v))
+	case v >= math.MinInt8:
+		w.b = append(w.b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		w.b = binary.BigEndian.AppendUint16(append(w.b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		w.b = binary.BigEndian.AppendUint32(append(w.b, 0xd2), uint32(v))
+	default:
+		w.b = binary.BigEndian.AppendUint64(append(w.b, 0xd3), uint64(v))
+	}
+}
+
+func (w *msgpackEncoder) writeString(s string) {
+	if len(s) <= math.MaxUint8 && len(s) >= 32 {
+		w.b = append(w.b, 0xd9, byte(len(s)))
+	} else {
+		w.head(0xa0, 5, 0xda, 0xdb, len(s))
+	}
+	w.b = append(w.b, s...)
+}
+
+func (w *msgpackEncoder) writeBool(v bool) {
+	if v {
+		w.b = append(w.b, 0xc3)
+		return
+	}
+
+	w.b = append(w.b, 0xc2)
+}
+
+// writeTime writes t with the smallest of the 32, 64 and 96 bit timestamp formats that holds it.
+func (w *msgpackEncoder) writeTime(t time.Time) {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	switch {
+	case sec>>34 != 0:
+		w.b = append(w.b, 0xc7, 12, msgpackTimestamp)
+		w.b = binary.BigEndian.AppendUint32(w.b, uint32(nsec))
+		w.b = binary.BigEndian.AppendUint64(w.b, uint64(sec))
+	case nsec == 0 && sec <= math.MaxUint32:
+		w.b = binary.BigEndian.AppendUint32(append(w.b, 0xd6, msgpackTimestamp), uint32(sec))
+	default:
+		w.b = binary.BigEndian.AppendUint64(append(w.b, 0xd7, msgpackTimestamp), nsec<<34|uint64(sec))
+	}
+}
+
+type msgpackDecoder struct {
+	binaryReader
+}
+
+// readLen reads a fix type holding the length in its low bits, or the 8, 16 or 32 bit type.
+// t8 is 0 for types without an 8 bit form.
+func (r *msgpackDecoder) readLen(name string, fix, mask, t8, t16, t32 byte) (int, error) {
+	c, err := r.next()
+	if err != nil {
+		return 0, err
+	}
+
+	var n uint64
+	switch {
+	case c&^mask == fix:
+		n = uint64(c & mask)
+	case t8 != 0 && c == t8:
+		n, err = r.uint(1)
+	case c == t16:
+		n, err = r.uint(2)
+	case c == t32:
+		n, err = r.uint(4)
+	default:
+		return 0, fmt.Errorf("jerrors: msgpack: expected %s, got 0x%02x", name, c)
+	}
+
+	return int(n), err
+}
+
+func (r *msgpackDecoder) readMap() (int, error) {
+	return r.readLen("map", 0x80, 0x0f, 0, 0xde, 0xdf)
+}
+
+func (r *msgpackDecoder) readArray() (int, error) {
+	return r.readLen("array", 0x90, 0x0f, 0, 0xdc, 0xdd)
+}
+
+func (r *msgpackDecoder) readString() (string, error) {
+	n, err := r.readLen("string", 0xa0, 0x1f, 0xd9, 0xda, 0xdb)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := r.take(uint64(n))
+	return string(b), err
+}
+
+func (r *msgpackDecoder) readInt() (int64, error) {
+	c, err := r.next()
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case c < 0x80:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0xcc && c <= 0xcf:
+		v, err := r.uint(1 << (c - 0xcc))
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("jerrors: msgpack: integer %d overflows int64", v)
+		}
+		return int64(v), err
+	case c >= 0xd0 && c <= 0xd3:
+		size := 1 << (c - 0xd0)
+		v, err := r.uint(size)
+		// Sign extend from the encoded size.
+		shift := 64 - 8*size
+		return int64(v<<shift) >> shift, err
+	}
+
+	return 0, fmt.Errorf("jerrors: msgpack: expected integer, got 0x%02x", c)
+}
+
+func (r *msgpackDecoder) readUint() (uint64, error) {
+	v, err := r.readInt()
+	if err == nil && v < 0 {
+		return 0, fmt.Errorf("jerrors: msgpack: expected unsigned integer, got %d", v)
+	}
+
+	return uint64(v), err
+}
+
+func (r *msgpackDecoder) readBool() (bool, error) {
+	c, err := r.next()
+	if err != nil {
+		return false, err
+	}
+
+	switch c {
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	}
+
+	return false, fmt.Errorf("jerrors: msgpack: expected bool, got 0x%02x", c)
+}
+
+// readTime reads a timestamp extension in the 32, 64 or 96 bit format.
+func (r *msgpackDecoder) readTime() (time.Time, error) {
+	c, err := r.next()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var n uint64
+	switch c {
+	case 0xd6:
+		n = 4
+	case 0xd7:
+		n = 8
+	case 0xc7:
+		if n, err = r.uint(1); err != nil {
+			return time.Time{}, err
+		}
+	default:
+		return time.Time{}, fmt.Errorf("jerrors: msgpack: expected timestamp, got 0x%02x", c)
+	}
+
+	if t, err := r.next(); err != nil || t != msgpackTimestamp {
+		return time.Time{}, fmt.Errorf("jerrors: msgpack: expected timestamp extension")
+	}
+
+	b, err := r.take(n)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b))), nil
+	}
+
+	return time.Time{}, fmt.Errorf("jerrors: msgpack: invalid timestamp length %d", n)
+}
+
+func (r *msgpackDecoder) skip() error { return r.skipNested(0) }
+
+// skipNested skips the next item, which is depth items deep in the value being skipped.
+func (r *msgpackDecoder) skipNested(depth int) error {
+	if depth > maxBinaryDepth {
+		return errBinaryDepth
+	}
+
+	c, err := r.next()
+	if err != nil {
+		return err
+	}
+
+	// items is how many values follow, size how many bytes follow and lenBytes how many bytes
+	// hold the size.
+	var items, size uint64
+	lenBytes := 0
+	switch {
+	case c < 0x80 || c >= 0xe0 || c == 0xc0 || c == 0xc2 || c == 0xc3:
+	case c <= 0x8f:
+		items = uint64(c&0x0f) * 2
+	case c <= 0x9f:
+		items = uint64(c & 0x0f)
+	case c <= 0xbf:
+		size = uint64(c & 0x1f)
+	case c >= 0xc4 && c <= 0xc6: // bin
+		lenBytes = 1 << (c - 0xc4)
+	case c >= 0xc7 && c <= 0xc9: // ext
+		lenBytes = 1 << (c - 0xc7)
+		size = 1
+	case c == 0xca:
+		size = 4
+	case c == 0xcb:
+		size = 8
+	case c >= 0xcc && c <= 0xd3:
+		size = 1 << ((c - 0xcc) % 4)
+	case c >= 0xd4 && c <= 0xd8: // fixext
+		size = 1 + 1<<(c-0xd4)
+	case c >= 0xd9 && c <= 0xdb: // str
+		lenBytes = 1 << (c - 0xd9)
+	case c == 0xdc || c == 0xdd:
+		n, err := r.uint(2 << (c - 0xdc))
+		if err != nil {
+			return err
+		}
+		items = n
+	case c == 0xde || c == 0xdf:
+		n, err := r.uint(2 << (c - 0xde))
+		if err != nil {
+			return err
+		}
+		items = n * 2
+	default:
+		return fmt.Errorf("jerrors: msgpack: invalid type 0x%02x", c)
+	}
+
+	if lenBytes > 0 {
+		n, err := r.uint(lenBytes)
+		if err != nil {
+			return err
+		}
+		size += n
+	}
+
+	if _, err := r.take(size); err != nil {
+		return err
+	}
+
+	if items > uint64(len(r.b)) {
+		return fmt.Errorf("jerrors: msgpack: %d items do not fit in %d bytes", items, len(r.b))
+	}
+
+	for i := uint64(0); i < items; i++ {
+		if err := r.skipNested(depth + 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package jerrors
+
+import (
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// packageLevels holds the active per package minimum Levels. nil when none are set.
+var packageLevels atomic.Pointer[packageLevelRules]
+
+type packageLevelRule struct {
+	pattern string
+	level   Level
+}
+
+type packageLevelRules struct {
+	rules []packageLevelRule
+	// cache maps a call site's program counter to its resolved Level. 0 means no rule matched.
+	cache sync.Map
+}
+
+// SetPackageLevels sets minimum Levels for the packages Log is called from, overriding the
+// Logging Level. spec is a comma separated list of pattern=level rules such as
+// "github.com/acme/db/*=debug,github.com/acme/cache=error". Patterns are matched with
+// path.Match and a pattern ending in "/*" also matches every nested package. The first
+// matching rule wins. Pass "" to remove all rules. Bad rules are returned as an *Errors and
+// leave the current rules in place.
+func SetPackageLevels(spec string) error {
+	errs := New()
+	var rules []packageLevelRule
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		pattern, level, ok := strings.Cut(rule, "=")
+		pattern = strings.TrimSpace(pattern)
+		l := GetLevel(strings.TrimSpace(level))
+		if _, err := path.Match(pattern, ""); !ok || pattern == "" || l == 0 || err != nil {
+			errs.NewError(ERROR, "invalid package level", "rule", rule)
+			continue
+		}
+
+		rules = append(rules, packageLevelRule{pattern: pattern, level: l})
+	}
+
+	if !errs.IsEmpty() {
+		return &errs
+	}
+
+	if len(rules) == 0 {
+		packageLevels.Store(nil)
+		return nil
+	}
+
+	packageLevels.Store(&packageLevelRules{rules: rules})
+	return nil
+}
+
+// PackageLevel returns the minimum Level set for pkg with SetPackageLevels. Returns false if no
+// rule matches.
+func PackageLevel(pkg string) (Level, bool) {
+	p := packageLevels.Load()
+	if p == nil {
+		return 0, false
+	}
+
+	l := p.match(pkg)
+	return l, l != 0
+}
+
+// minLevel returns the minimum Level to log for the call site skip frames above the caller of
+// minLevel. Falls back to the Logging Level and false when no package rule matches.
+func minLevel(skip int) (Level, bool) {
+	p := packageLevels.Load()
+	if p == nil {
+		return loggingLevel.Level(), false
+	}
+
+	pcs := callerPCs(skip+1, 1)
+	if len(pcs) == 0 {
+		return loggingLevel.Level(), false
+	}
+
+	l, ok := p.cache.Load(pcs[0])
+	if !ok {
+		f, _ := runtime.CallersFrames(pcs).Next()
+		l, _ = p.cache.LoadOrStore(pcs[0], p.match(funcPackage(f.Function)))
+	}
+
+	if l.(Level) == 0 {
+		return loggingLevel.Level(), false
+	}
+
+	return l.(Level), true
+}
+
+func (p *packageLevelRules) match(pkg string) Level {
+	for _, r := range p.rules {
+		if ok, _ := path.Match(r.pattern, pkg); ok {
+			return r.level
+		}
+
+		if prefix, ok := strings.CutSuffix(r.pattern, "/*"); ok && strings.HasPrefix(pkg, prefix+"/") {
+			return r.level
+		}
+	}
+
+	return 0
+}
+
+// funcPackage returns the package path of a fully qualified function name such as
+// "github.com/acme/db.(*Conn).Query". The runtime escapes dots in the last path element as %2e.
+func funcPackage(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.Index(fn[slash+1:], "."); dot >= 0 {
+		fn = fn[:slash+1+dot]
+	}
+
+	return strings.ReplaceAll(fn, "%2e", ".")
+}
@@ -0,0 +1,92 @@
+package jerrors
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageLevelFuncPackage(t *testing.T) {
+	require.Equal(t, "github.com/acme/db", funcPackage("github.com/acme/db.(*Conn).Query"))
+	require.Equal(t, "github.com/acme/db", funcPackage("github.com/acme/db.Open.func1"))
+	require.Equal(t, "main", funcPackage("main.main"))
+	require.Equal(t, "gopkg.in/yaml.v3", funcPackage("gopkg.in/yaml%2ev3.Marshal"))
+}
+
+func TestPackageLevelSetPackageLevels(t *testing.T) {
+	defer SetPackageLevels("")
+
+	require.Nil(t, SetPackageLevels("github.com/acme/db/*=debug, github.com/acme/cache=error"))
+
+	l, ok := PackageLevel("github.com/acme/db/sql")
+	require.True(t, ok)
+	require.Equal(t, DEBUG, l)
+
+	l, ok = PackageLevel("github.com/acme/db/sql/driver")
+	require.True(t, ok)
+	require.Equal(t, DEBUG, l)
+
+	l, ok = PackageLevel("github.com/acme/cache")
+	require.True(t, ok)
+	require.Equal(t, ERROR, l)
+
+	_, ok = PackageLevel("github.com/acme/cache/lru")
+	require.False(t, ok)
+
+	// Bad rules keep the current rules.
+	err := SetPackageLevels("github.com/acme/api=loud,nolevel,=info,[=warn")
+	require.Error(t, err)
+	require.Equal(t, 4, len(err.(*Errors).Errors))
+	_, ok = PackageLevel("github.com/acme/cache")
+	require.True(t, ok)
+
+	require.Nil(t, SetPackageLevels(""))
+	_, ok = PackageLevel("github.com/acme/cache")
+	require.False(t, ok)
+}
+
+func TestPackageLevelLog(t *testing.T) {
+	SetConfig(DefaultConfig())
+	defer SetPackageLevels("")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	// Lower the level for this package only.
+	require.Nil(t, SetPackageLevels("github.com/chadeldridge/*=debug"))
+	err := debugErr
+	for i := 0; i < 2; i++ {
+		err.Log()
+	}
+	require.Contains(t, buf.String(), `"level":"debug"`)
+
+	// Raise the level for this package only.
+	buf.Reset()
+	require.Nil(t, SetPackageLevels("github.com/acme/*=debug,github.com/chadeldridge/jerrors=error"))
+	err = warnErr
+	err.Log()
+	require.Empty(t, buf.String())
+
+	errs := New()
+	errs.Add(debugErr)
+	errs.Add(warnErr)
+	errs.Add(errorErr)
+	errs.Log()
+	require.NotContains(t, buf.String(), `"level":"debug"`)
+	require.NotContains(t, buf.String(), `"level":"warn"`)
+	require.Contains(t, buf.String(), `"level":"error"`)
+
+	// No matching rule uses the Logging Level.
+	buf.Reset()
+	require.Nil(t, SetPackageLevels("github.com/acme/*=debug"))
+	err = debugErr
+	err.Log()
+	err = infoErr
+	err.Log()
+	require.NotContains(t, buf.String(), `"level":"debug"`)
+	require.Contains(t, buf.String(), `"level":"info"`)
+}
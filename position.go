@@ -0,0 +1,216 @@
+package jerrors
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is a location in a source file. Line and Column start at 1 and Column counts bytes, as
+// in go/token. EndLine and EndColumn optionally mark the end of a span, with EndColumn pointing
+// just past its last byte.
+type Position struct {
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+}
+
+// IsValid returns true if the Position has a Line.
+func (p *Position) IsValid() bool { return p != nil && p.Line > 0 }
+
+// String returns the Position as file:line:col. The file and column are left out when unset.
+func (p Position) String() string {
+	s := strconv.Itoa(p.Line)
+	if p.Column > 0 {
+		s += ":" + strconv.Itoa(p.Column)
+	}
+
+	if p.File != "" {
+		s = p.File + ":" + s
+	}
+
+	return s
+}
+
+// Equal returns true if both Positions are nil or equal.
+func (p *Position) Equal(other *Position) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+
+	return *p == *other
+}
+
+// Before reports whether p sorts before other: by File, then Line, then Column.
+func (p Position) Before(other Position) bool {
+	if p.File != other.File {
+		return p.File < other.File
+	}
+
+	if p.Line != other.Line {
+		return p.Line < other.Line
+	}
+
+	return p.Column < other.Column
+}
+
+// At returns a copy of the Error with its Position set to pos.
+// Example:
+// pos := jerrors.Position{File: "app.dsl", Line: 3, Column: 9}
+// err := jerrors.NewError(jerrors.ERROR, "unknown identifier").At(pos)
+func (e Error) At(pos Position) Error {
+	c := e.Clone()
+	c.Position = &pos
+	return c
+}
+
+// At creates a new Error at pos and adds it to the List.
+func (e *Errors) At(pos Position, level Level, msg string, args ...interface{}) {
+	err := NewError(level, msg, args...)
+	err.Position = &pos
+	e.Add(err)
+}
+
+// Compact returns the Error as "file:line:col: level: message", the format editors' problem
+// matchers expect. The position is left out if the Error has none.
+func (e Error) Compact() string {
+	s := e.Level.String() + ": " + e.Message
+	if e.Level == 0 {
+		s = e.Message
+	}
+
+	if e.Position.IsValid() {
+		s = e.Position.String() + ": " + s
+	}
+
+	return s
+}
+
+// Snippet renders the source line of the Error's Position from src with a caret under the column,
+// or an underline to the end of the span. Returns "" if the Error has no Position or the line is
+// not in src.
+// Example output:
+//
+//	3 | let x = fo + 1
+//	  |         ^^
+func (e Error) Snippet(src []byte) string {
+	p := e.Position
+	if !p.IsValid() {
+		return ""
+	}
+
+	line, ok := sourceLine(src, p.Line)
+	if !ok {
+		return ""
+	}
+
+	num := strconv.Itoa(p.Line)
+	gutter := strings.Repeat(" ", len(num))
+	s := num + " | " + line + "\n"
+	if p.Column <= 0 {
+		return s
+	}
+
+	// Byte offsets of the underlined text in line.
+	start := min(p.Column, len(line)+1) - 1
+	end := start + 1
+	switch {
+	case p.EndLine > p.Line:
+		end = len(line)
+	case p.EndColumn > p.Column:
+		end = p.EndColumn - 1
+	}
+	end = max(min(end, len(line)), start)
+
+	carets := max(utf8.RuneCountInString(line[start:end]), 1)
+	return s + gutter + " | " + caretPadding(line[:start]) + strings.Repeat("^", carets) + "\n"
+}
+
+// Render returns Compact followed by the Snippet from src.
+func (e Error) Render(src []byte) string {
+	return e.Compact() + "\n" + e.Snippet(src)
+}
+
+// SortByPosition sorts the List by Position. Errors without a Position keep their order and come
+// last.
+func (e *Errors) SortByPosition() {
+	sort.SliceStable(e.Errors, func(i, j int) bool {
+		return positionLess(e.Errors[i].Position, e.Errors[j].Position)
+	})
+}
+
+// Compact returns every Error in the List and its groups in the Compact format, one per line,
+// sorted by Position.
+func (e *Errors) Compact() string {
+	var b strings.Builder
+	for _, err := range e.sortedByPosition() {
+		b.WriteString(err.Compact() + "\n")
+	}
+
+	return b.String()
+}
+
+// Render returns every Error in the List and its groups sorted by Position, each followed by its
+// Snippet. sources holds the contents of each file by name. Errors in other files are rendered
+// without a Snippet.
+func (e *Errors) Render(sources map[string][]byte) string {
+	var b strings.Builder
+	for _, err := range e.sortedByPosition() {
+		b.WriteString(err.Compact() + "\n")
+		if err.Position.IsValid() {
+			b.WriteString(err.Snippet(sources[err.Position.File]))
+		}
+	}
+
+	return b.String()
+}
+
+func (e *Errors) sortedByPosition() []Error {
+	all := append([]Error{}, e.Flatten()...)
+	sort.SliceStable(all, func(i, j int) bool { return positionLess(all[i].Position, all[j].Position) })
+	return all
+}
+
+func positionLess(a, b *Position) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() && !b.IsValid()
+	}
+
+	return a.Before(*b)
+}
+
+// sourceLine returns line n of src, starting at 1, without its line ending.
+func sourceLine(src []byte, n int) (string, bool) {
+	for i := 1; len(src) > 0; i++ {
+		line := src
+		if j := bytes.IndexByte(src, '\n'); j >= 0 {
+			line, src = src[:j], src[j+1:]
+		} else {
+			src = nil
+		}
+
+		if i == n {
+			return string(bytes.TrimSuffix(line, []byte("\r"))), true
+		}
+	}
+
+	return "", false
+}
+
+// caretPadding returns whitespace as wide as prefix, keeping tabs so the caret lines up.
+func caretPadding(prefix string) string {
+	var b strings.Builder
+	for _, r := range prefix {
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSource = "let a = 1\n\tlet x = fo + 1\r\nlet é = bar\n"
+
+func TestPositionString(t *testing.T) {
+	require.Equal(t, "app.dsl:3:9", Position{File: "app.dsl", Line: 3, Column: 9}.String())
+	require.Equal(t, "app.dsl:3", Position{File: "app.dsl", Line: 3}.String())
+	require.Equal(t, "3:9", Position{Line: 3, Column: 9}.String())
+
+	var p *Position
+	require.False(t, p.IsValid())
+	require.False(t, (&Position{}).IsValid())
+	require.True(t, p.Equal(nil))
+	require.False(t, p.Equal(&Position{Line: 1}))
+}
+
+func TestPositionAt(t *testing.T) {
+	SetConfig(DefaultConfig())
+	base := NewError(ERROR, "unknown identifier")
+	e := base.At(Position{File: "app.dsl", Line: 2, Column: 10})
+
+	require.Nil(t, base.Position)
+	require.Equal(t, 2, e.Position.Line)
+	require.False(t, base.Equal(e))
+
+	c := e.Clone()
+	c.Position.Line = 5
+	require.Equal(t, 2, e.Position.Line)
+
+	errs := New()
+	errs.At(Position{Line: 1}, WARN, testMessage)
+	require.Equal(t, 1, errs.First().Position.Line)
+	require.Equal(t, WARN, errs.Level)
+}
+
+func TestPositionCompact(t *testing.T) {
+	e := Error{Level: ERROR, Message: "unknown identifier", Position: &Position{File: "app.dsl", Line: 2, Column: 10}}
+	require.Equal(t, "app.dsl:2:10: error: unknown identifier", e.Compact())
+
+	e.Position = nil
+	require.Equal(t, "error: unknown identifier", e.Compact())
+
+	e.Level = 0
+	require.Equal(t, "unknown identifier", e.Compact())
+}
+
+func TestPositionSnippet(t *testing.T) {
+	src := []byte(testSource)
+	e := Error{Level: ERROR, Message: "unknown identifier", Position: &Position{Line: 2, Column: 10, EndColumn: 12}}
+	require.Equal(t, "2 | \tlet x = fo + 1\n  | \t        ^^\n", e.Snippet(src))
+
+	// A single caret without a span.
+	e.Position.EndColumn = 0
+	require.Equal(t, "2 | \tlet x = fo + 1\n  | \t        ^\n", e.Snippet(src))
+
+	// Spans over several lines are underlined to the end of the first line.
+	e.Position = &Position{Line: 2, Column: 10, EndLine: 3, EndColumn: 2}
+	require.Equal(t, "2 | \tlet x = fo + 1\n  | \t        ^^^^^^\n", e.Snippet(src))
+
+	// Columns count bytes, carets count characters.
+	e.Position = &Position{Line: 3, Column: 5, EndColumn: 7}
+	require.Equal(t, "3 | let é = bar\n  |     ^\n", e.Snippet(src))
+
+	// No column shows only the line.
+	e.Position = &Position{Line: 1}
+	require.Equal(t, "1 | let a = 1\n", e.Snippet(src))
+
+	e.Position = &Position{Line: 10, Column: 1}
+	require.Equal(t, "", e.Snippet(src))
+	e.Position = nil
+	require.Equal(t, "", e.Snippet(src))
+
+	e.Position = &Position{File: "app.dsl", Line: 1, Column: 5}
+	require.Equal(t, "app.dsl:1:5: error: unknown identifier\n1 | let a = 1\n  |     ^\n", e.Render(src))
+}
+
+func TestPositionSort(t *testing.T) {
+	SetConfig(DefaultConfig())
+	errs := New()
+	errs.NewError(WARN, "no position")
+	errs.At(Position{File: "b.dsl", Line: 1, Column: 1}, ERROR, "b1")
+	errs.At(Position{File: "a.dsl", Line: 2, Column: 5}, ERROR, "a2")
+	errs.At(Position{File: "a.dsl", Line: 2, Column: 1}, WARN, "a1")
+	errs.Group("lib").At(Position{File: "a.dsl", Line: 1}, INFO, "lib")
+
+	require.Equal(t, `a.dsl:1: info: lib
+a.dsl:2:1: warn: a1
+a.dsl:2:5: error: a2
+b.dsl:1:1: error: b1
+warn: no position
+`, errs.Compact())
+
+	sources := map[string][]byte{"a.dsl": []byte("x\nlet y = z\n")}
+	require.Equal(t, `a.dsl:1: info: lib
+1 | x
+a.dsl:2:1: warn: a1
+2 | let y = z
+  | ^
+a.dsl:2:5: error: a2
+2 | let y = z
+  |     ^
+b.dsl:1:1: error: b1
+warn: no position
+`, errs.Render(sources))
+
+	errs.SortByPosition()
+	require.Equal(t, "a1", errs.Errors[0].Message)
+	require.Equal(t, "no position", errs.Errors[3].Message)
+}
+
+func TestPositionJSON(t *testing.T) {
+	SetConfig(DefaultConfig())
+	e := Error{Level: ERROR, Message: testMessage, Position: &Position{File: "app.dsl", Line: 2, Column: 10, EndColumn: 12}}
+
+	b, err := json.Marshal(e)
+	require.Nil(t, err)
+	require.Equal(t, `{"level":"error","message":"test error","position":{"file":"app.dsl","line":2,"column":10,"end_column":12}}`, string(b))
+
+	var got Error
+	require.Nil(t, json.Unmarshal(b, &got))
+	require.True(t, e.Equal(got))
+}
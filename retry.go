@@ -0,0 +1,200 @@
+package jerrors
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+// Metadata keys Retry adds to the Error of each attempt.
+const (
+	AttemptKey = "attempt"
+	DelayKey   = "delay"
+)
+
+// IsTemporary reports whether err, or any error it wraps, is a temporary failure: an Error with
+// Temporary set, or an error with a Temporary() or Timeout() method returning true, such as a
+// net.Error.
+func IsTemporary(err error) bool {
+	var e Error
+	if errors.As(err, &e) && e.Temporary {
+		return true
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// IsRetryable reports whether err is worth trying again: an Error with Retryable set, or any
+// temporary failure. Context cancellation is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var e Error
+	if errors.As(err, &e) && e.Retryable {
+		return true
+	}
+
+	return IsTemporary(err)
+}
+
+// RetryAfter returns the RetryAfter of the first Error wrapped by err. Returns 0 if there is none.
+func RetryAfter(err error) time.Duration {
+	var e Error
+	if errors.As(err, &e) {
+		return e.RetryAfter
+	}
+
+	return 0
+}
+
+// Classify sets Temporary, Retryable and RetryAfter from err.
+// Example:
+// e := jerrors.NewError(jerrors.ERROR, err.Error())
+// e.Classify(err)
+func (e *Error) Classify(err error) {
+	e.Temporary = IsTemporary(err)
+	e.Retryable = IsRetryable(err)
+	e.RetryAfter = RetryAfter(err)
+}
+
+// RetryPolicy controls how often and how long Retry waits between attempts.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. 0 retries until ctx is done.
+	MaxAttempts int
+	// InitialDelay is the wait after the first failed attempt.
+	InitialDelay time.Duration
+	// MaxDelay caps the wait between attempts. 0 does not cap it.
+	MaxDelay time.Duration
+	// Multiplier grows the delay after each attempt. Values below 1 keep it constant.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction of it, between 0 and 1.
+	Jitter float64
+	// Retryable decides whether an error is retried. Defaults to IsRetryable.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy makes up to 5 attempts, waiting 100ms, 200ms, 400ms and 800ms with 20%
+// jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  5,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+		Retryable:    IsRetryable,
+	}
+}
+
+// Delay returns the wait after the given failed attempt, starting at 1, without jitter.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	d := float64(p.InitialDelay)
+	for i := 1; i < attempt && p.Multiplier > 1; i++ {
+		d *= p.Multiplier
+		if p.MaxDelay > 0 && d >= float64(p.MaxDelay) {
+			break
+		}
+	}
+
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+
+	return time.Duration(d)
+}
+
+// jitter randomizes d by up to Jitter of it in either direction.
+func (p RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 || d <= 0 {
+		return d
+	}
+
+	j := min(p.Jitter, 1)
+	return time.Duration(float64(d) * (1 - j + 2*j*rand.Float64()))
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable, runs out of attempts
+// or ctx is done. A RetryAfter longer than the policy's delay is honored. The returned Errors
+// holds an Error for every failed attempt with AttemptKey and DelayKey Metadata. Attempts that
+// were retried are WARN. The final failure keeps its Level, or is ERROR if fn did not return an
+// Error, so the returned Errors IsError only if Retry gave up. If ctx is done before an attempt,
+// including the first, fn is not called and an Error for ctx.Err() is added with that attempt's
+// number.
+func Retry(ctx context.Context, p RetryPolicy, fn func(ctx context.Context) error) Errors {
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+
+	errs := New()
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			errs.Add(attemptError(err, attempt))
+			return errs
+		}
+
+		err := fn(ctx)
+		if err == nil {
+			return errs
+		}
+
+		e := attemptError(err, attempt)
+		if !p.Retryable(err) || (p.MaxAttempts > 0 && attempt >= p.MaxAttempts) {
+			errs.Add(e)
+			return errs
+		}
+
+		delay := p.jitter(p.Delay(attempt))
+		if after := RetryAfter(err); after > delay {
+			delay = after
+		}
+
+		e.Level = WARN
+		e.setMetadata(DelayKey, delay.String())
+		errs.Add(e)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// attemptError converts err to an Error for the given attempt, copying it if it is an Error.
+func attemptError(err error, attempt int) Error {
+	var e Error
+	if errors.As(err, &e) {
+		e = e.Clone()
+		if e.Metadata == nil {
+			e.Metadata = make(map[string]string)
+		}
+	} else {
+		e = Error{Level: ERROR, Message: err.Error(), Metadata: make(map[string]string)}
+		if c := config.Load(); c.LogTime {
+			t := now()
+			if c.UTC {
+				t = t.UTC()
+			}
+			e.Time = &t
+		}
+		e.Classify(err)
+	}
+
+	if e.Level == 0 {
+		e.Level = ERROR
+	}
+	e.setMetadata(AttemptKey, strconv.Itoa(attempt))
+
+	return e
+}
@@ -0,0 +1,171 @@
+package jerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return false }
+
+func testRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.InitialDelay = time.Millisecond
+	p.Jitter = 0
+	return p
+}
+
+func TestRetryClassify(t *testing.T) {
+	require.True(t, IsTemporary(timeoutError{}))
+	require.True(t, IsRetryable(fmt.Errorf("dial: %w", timeoutError{})))
+	require.True(t, IsTemporary(context.DeadlineExceeded))
+	require.False(t, IsRetryable(context.Canceled))
+	require.False(t, IsRetryable(errors.New("bad request")))
+	require.False(t, IsRetryable(nil))
+
+	e := Error{Level: ERROR, Message: testMessage, Retryable: true, RetryAfter: time.Second}
+	require.True(t, IsRetryable(fmt.Errorf("wrapped: %w", e)))
+	require.False(t, IsTemporary(e))
+	require.Equal(t, time.Second, RetryAfter(fmt.Errorf("wrapped: %w", e)))
+
+	var c Error
+	c.Classify(timeoutError{})
+	require.True(t, c.Temporary)
+	require.True(t, c.Retryable)
+}
+
+func TestRetryJSON(t *testing.T) {
+	SetConfig(DefaultConfig())
+	e := Error{Level: ERROR, Message: testMessage, Retryable: true, Temporary: true, RetryAfter: 1500 * time.Millisecond}
+
+	b, err := json.Marshal(e)
+	require.Nil(t, err)
+	require.Equal(t, `{"level":"error","message":"test error","retryable":true,"temporary":true,"retry_after":"1.5s"}`, string(b))
+
+	var got Error
+	require.Nil(t, json.Unmarshal(b, &got))
+	require.True(t, e.Equal(got))
+
+	require.Error(t, json.Unmarshal([]byte(`{"retry_after":"soon"}`), &got))
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+	require.Equal(t, 100*time.Millisecond, p.Delay(1))
+	require.Equal(t, 200*time.Millisecond, p.Delay(2))
+	require.Equal(t, 800*time.Millisecond, p.Delay(4))
+	require.Equal(t, time.Second, p.Delay(5))
+	require.Equal(t, time.Second, p.Delay(100))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.jitter(time.Second)
+		require.GreaterOrEqual(t, d, 500*time.Millisecond)
+		require.LessOrEqual(t, d, 1500*time.Millisecond)
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	SetConfig(DefaultConfig())
+	calls := 0
+	errs := Retry(context.Background(), testRetryPolicy(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return timeoutError{}
+		}
+		return nil
+	})
+
+	require.Equal(t, 3, calls)
+	require.Len(t, errs.Errors, 2)
+	require.False(t, errs.IsError())
+	for i, e := range errs.Errors {
+		require.Equal(t, WARN, e.Level)
+		require.Equal(t, fmt.Sprint(i+1), e.Metadata[AttemptKey])
+		require.Equal(t, testRetryPolicy().Delay(i+1).String(), e.Metadata[DelayKey])
+		require.True(t, e.Temporary)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	SetConfig(DefaultConfig())
+	p := testRetryPolicy()
+	p.MaxAttempts = 3
+
+	errs := Retry(context.Background(), p, func(ctx context.Context) error {
+		return Error{Level: FATAL, Message: testMessage, Retryable: true}
+	})
+
+	require.Len(t, errs.Errors, 3)
+	require.True(t, errs.IsFatal())
+	last := errs.Last()
+	require.Equal(t, FATAL, last.Level)
+	require.Equal(t, "3", last.Metadata[AttemptKey])
+	require.NotContains(t, last.Metadata, DelayKey)
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	SetConfig(DefaultConfig())
+	calls := 0
+	errs := Retry(context.Background(), testRetryPolicy(), func(ctx context.Context) error {
+		calls++
+		return errors.New("bad request")
+	})
+
+	require.Equal(t, 1, calls)
+	require.Len(t, errs.Errors, 1)
+	require.Equal(t, ERROR, errs.First().Level)
+	require.Equal(t, "bad request", errs.First().Message)
+}
+
+func TestRetryRetryAfter(t *testing.T) {
+	SetConfig(DefaultConfig())
+	p := testRetryPolicy()
+	p.MaxAttempts = 2
+
+	errs := Retry(context.Background(), p, func(ctx context.Context) error {
+		return Error{Level: ERROR, Message: "rate limited", Retryable: true, RetryAfter: 5 * time.Millisecond}
+	})
+
+	require.Len(t, errs.Errors, 2)
+	require.Equal(t, "5ms", errs.First().Metadata[DelayKey])
+}
+
+func TestRetryContext(t *testing.T) {
+	SetConfig(DefaultConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	p := testRetryPolicy()
+	p.MaxAttempts = 0
+	p.InitialDelay = time.Hour
+
+	errs := Retry(ctx, p, func(ctx context.Context) error {
+		cancel()
+		return timeoutError{}
+	})
+
+	require.Len(t, errs.Errors, 2)
+	require.True(t, errs.IsError())
+	require.Equal(t, context.Canceled.Error(), errs.Last().Message)
+	require.Equal(t, "1", errs.First().Metadata[AttemptKey])
+	require.Equal(t, "2", errs.Last().Metadata[AttemptKey])
+
+	// fn is not called if ctx is already done.
+	called := false
+	errs = Retry(ctx, p, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	require.False(t, called)
+	require.Len(t, errs.Errors, 1)
+	require.Equal(t, "1", errs.First().Metadata[AttemptKey])
+	require.True(t, errs.IsError())
+}
@@ -0,0 +1,388 @@
+package jerrors
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp layout used in rotated file names. It avoids ':' so backups
+// are valid file names on every platform.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateConfig holds the rotation options for a RotatingFile.
+type RotateConfig struct {
+	// MaxSize is the size in bytes a file may reach before being rotated. 0 disables size rotation.
+	MaxSize int64
+	// Interval is how long a file is written to before being rotated. 0 disables time rotation.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files to keep. 0 keeps all backups.
+	MaxBackups int
+	// Compress gzips rotated files.
+	Compress bool
+	// LocalTime uses local time instead of UTC when naming rotated files.
+	LocalTime bool
+	// FileMode is the permission used when creating new log files.
+	FileMode os.FileMode
+}
+
+// DefaultRotateConfig rotates daily or at 100MB, keeping 7 uncompressed backups.
+func DefaultRotateConfig() RotateConfig {
+	return RotateConfig{
+		MaxSize:    100 * 1024 * 1024,
+		Interval:   24 * time.Hour,
+		MaxBackups: 7,
+		Compress:   false,
+		LocalTime:  false,
+		FileMode:   0o644,
+	}
+}
+
+// RotatingFile is an io.Writer that writes to Filename and rotates it based on size and/or time.
+// Rotated files are renamed to name-<timestamp>.ext and optionally gzipped in the background.
+// It can be used directly with SetLogOutput.
+// Example:
+// f, err := jerrors.NewRotatingFile("/var/log/app/errors.log", jerrors.DefaultRotateConfig())
+// jerrors.SetLogOutput(f)
+type RotatingFile struct {
+	Filename string
+	config   RotateConfig
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	// Rotated files are compressed and pruned in the background, outside mu, in rotation order.
+	// done is closed when the last rotation's work is finished. backgroundErr holds the first
+	// error hit and pending counts the rotations still running.
+	done          chan struct{}
+	background    sync.Mutex
+	backgroundErr error
+	pending       sync.WaitGroup
+}
+
+// NewRotatingFile opens or creates filename for appending and returns a RotatingFile.
+func NewRotatingFile(filename string, c RotateConfig) (*RotatingFile, error) {
+	if filename == "" {
+		return nil, fmt.Errorf("jerrors: rotating file name cannot be empty")
+	}
+
+	if c.FileMode == 0 {
+		c.FileMode = 0o644
+	}
+
+	r := &RotatingFile{Filename: filename, config: c}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Write writes p to the current file, rotating first if p would exceed MaxSize or the Interval
+// has elapsed.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, moves it to a timestamped backup and opens a new file.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rotate()
+}
+
+// Reopen closes and reopens Filename without rotating. Use this after an external tool has
+// moved the file.
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.close(); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+// Close closes the current file and waits for rotated files to finish compressing. It returns any
+// error hit while compressing or pruning them. A later Write will reopen the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	err := r.close()
+	r.mu.Unlock()
+
+	r.pending.Wait()
+	r.background.Lock()
+	defer r.background.Unlock()
+
+	err = errors.Join(err, r.backgroundErr)
+	r.backgroundErr = nil
+	return err
+}
+
+// Backups returns the rotated files for Filename, oldest first.
+func (r *RotatingFile) Backups() ([]string, error) {
+	dir := filepath.Dir(r.Filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		name string
+		t    time.Time
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if t, ok := r.backupTime(entry.Name()); ok {
+			backups = append(backups, backup{filepath.Join(dir, entry.Name()), t})
+		}
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].t.Before(backups[j].t) })
+
+	names := make([]string, 0, len(backups))
+	for _, b := range backups {
+		names = append(names, b.name)
+	}
+
+	return names, nil
+}
+
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.config.MaxSize > 0 && r.size > 0 && r.size+n > r.config.MaxSize {
+		return true
+	}
+
+	return r.config.Interval > 0 && clockTime().Sub(r.opened) >= r.config.Interval
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.Filename), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(r.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, r.config.FileMode)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	r.file = f
+	r.size = info.Size()
+	r.opened = clockTime()
+	return nil
+}
+
+func (r *RotatingFile) close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.close(); err != nil {
+		return err
+	}
+
+	backup := r.backupName(clockTime())
+	if err := os.Rename(r.Filename, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	// Compress in the background so Write does not hold mu while a whole file is gzipped.
+	if r.config.Compress {
+		prev, done := r.done, make(chan struct{})
+		r.done = done
+		r.pending.Add(1)
+		go r.compress(backup, prev, done)
+		return nil
+	}
+
+	return r.prune(backup)
+}
+
+// compress gzips backup once the rotation before it, prev, is done and prunes old backups,
+// keeping the first error for Close. It closes done when finished.
+func (r *RotatingFile) compress(backup string, prev <-chan struct{}, done chan<- struct{}) {
+	defer r.pending.Done()
+	defer close(done)
+
+	if prev != nil {
+		<-prev
+	}
+
+	err := compressFile(backup)
+	if err == nil {
+		err = r.prune(backup + ".gz")
+	}
+
+	r.background.Lock()
+	defer r.background.Unlock()
+
+	if err != nil && r.backgroundErr == nil {
+		r.backgroundErr = err
+	}
+}
+
+// prune removes the oldest backups beyond MaxBackups, stopping at last so backups rotated after it
+// and still waiting to be compressed are kept.
+func (r *RotatingFile) prune(last string) error {
+	if r.config.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := r.Backups()
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > r.config.MaxBackups {
+		oldest := backups[0]
+		if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+
+		if oldest == last {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(r.Filename)
+	base := filepath.Base(r.Filename)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return dir, prefix, ext
+}
+
+func (r *RotatingFile) backupName(t time.Time) string {
+	if !r.config.LocalTime {
+		t = t.UTC()
+	}
+
+	dir, prefix, ext := r.nameParts()
+	name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+
+	// Never clobber an existing backup if two rotations land in the same millisecond.
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s%s.%d%s", prefix, t.Format(backupTimeFormat), i, ext))
+	}
+
+	return name
+}
+
+// backupTime parses the timestamp out of a backup file name.
+func (r *RotatingFile) backupTime(name string) (time.Time, bool) {
+	_, prefix, ext := r.nameParts()
+	name = strings.TrimSuffix(name, ".gz")
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+		return time.Time{}, false
+	}
+
+	ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+	if len(ts) > len(backupTimeFormat) {
+		ts = ts[:len(backupTimeFormat)]
+	}
+
+	loc := time.UTC
+	if r.config.LocalTime {
+		loc = time.Local
+	}
+
+	t, err := time.ParseInLocation(backupTimeFormat, ts, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package jerrors
+
+// ReopenOnSIGHUP does nothing on platforms without SIGHUP.
+func (r *RotatingFile) ReopenOnSIGHUP() (stop func()) { return func() {} }
@@ -0,0 +1,164 @@
+package jerrors
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileSize(t *testing.T) {
+	clock := setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	name := filepath.Join(t.TempDir(), "errors.log")
+
+	c := DefaultRotateConfig()
+	c.MaxSize = 10
+	c.Interval = 0
+	f, err := NewRotatingFile(name, c)
+	require.Nil(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("12345678\n"))
+	require.Nil(t, err)
+
+	clock.Add(time.Second)
+	_, err = f.Write([]byte("abcdefgh\n"))
+	require.Nil(t, err)
+
+	backups, err := f.Backups()
+	require.Nil(t, err)
+	require.Len(t, backups, 1)
+	require.Equal(t, "errors-2024-07-18T13-00-01.000.log", filepath.Base(backups[0]))
+
+	b, err := os.ReadFile(backups[0])
+	require.Nil(t, err)
+	require.Equal(t, "12345678\n", string(b))
+
+	b, err = os.ReadFile(name)
+	require.Nil(t, err)
+	require.Equal(t, "abcdefgh\n", string(b))
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	clock := setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	name := filepath.Join(t.TempDir(), "errors.log")
+
+	c := DefaultRotateConfig()
+	c.MaxSize = 0
+	c.Interval = time.Hour
+	f, err := NewRotatingFile(name, c)
+	require.Nil(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("first\n"))
+	require.Nil(t, err)
+
+	clock.Add(30 * time.Minute)
+	_, err = f.Write([]byte("second\n"))
+	require.Nil(t, err)
+
+	backups, err := f.Backups()
+	require.Nil(t, err)
+	require.Len(t, backups, 0)
+
+	clock.Add(30 * time.Minute)
+	_, err = f.Write([]byte("third\n"))
+	require.Nil(t, err)
+
+	backups, err = f.Backups()
+	require.Nil(t, err)
+	require.Len(t, backups, 1)
+
+	b, err := os.ReadFile(name)
+	require.Nil(t, err)
+	require.Equal(t, "third\n", string(b))
+}
+
+func TestRotatingFileMaxBackupsCompress(t *testing.T) {
+	clock := setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	name := filepath.Join(t.TempDir(), "errors.log")
+
+	c := DefaultRotateConfig()
+	c.MaxBackups = 2
+	c.Compress = true
+	f, err := NewRotatingFile(name, c)
+	require.Nil(t, err)
+	defer f.Close()
+
+	for i := 0; i < 4; i++ {
+		_, err = f.Write([]byte("line\n"))
+		require.Nil(t, err)
+		clock.Add(time.Minute)
+		require.Nil(t, f.Rotate())
+	}
+
+	// Close waits for the background compression.
+	require.Nil(t, f.Close())
+	backups, err := f.Backups()
+	require.Nil(t, err)
+	require.Len(t, backups, 2)
+	require.Equal(t, "errors-2024-07-18T13-03-00.000.log.gz", filepath.Base(backups[0]))
+	require.Equal(t, "errors-2024-07-18T13-04-00.000.log.gz", filepath.Base(backups[1]))
+
+	gf, err := os.Open(backups[1])
+	require.Nil(t, err)
+	defer gf.Close()
+
+	gz, err := gzip.NewReader(gf)
+	require.Nil(t, err)
+	b, err := io.ReadAll(gz)
+	require.Nil(t, err)
+	require.Equal(t, "line\n", string(b))
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "errors.log")
+
+	f, err := NewRotatingFile(name, DefaultRotateConfig())
+	require.Nil(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("before\n"))
+	require.Nil(t, err)
+
+	// Simulate an external logrotate moving the file.
+	require.Nil(t, os.Rename(name, filepath.Join(dir, "moved.log")))
+	require.Nil(t, f.Reopen())
+
+	_, err = f.Write([]byte("after\n"))
+	require.Nil(t, err)
+
+	b, err := os.ReadFile(name)
+	require.Nil(t, err)
+	require.Equal(t, "after\n", string(b))
+}
+
+func TestRotatingFileSetLogOutput(t *testing.T) {
+	SetConfig(DefaultConfig())
+	name := filepath.Join(t.TempDir(), "errors.log")
+
+	f, err := NewRotatingFile(name, DefaultRotateConfig())
+	require.Nil(t, err)
+	defer f.Close()
+
+	SetLogOutput(f)
+	defer SetLogOutput(nil)
+
+	err2 := errorErr
+	err2.Log()
+
+	b, err := os.ReadFile(name)
+	require.Nil(t, err)
+	require.True(t, strings.Contains(string(b), testMessage))
+}
+
+func TestRotatingFileEmptyName(t *testing.T) {
+	_, err := NewRotatingFile("", DefaultRotateConfig())
+	require.Error(t, err)
+}
@@ -0,0 +1,260 @@
+package jerrors
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// sampler is the active Sampler used by Log. nil disables sampling.
+var (
+	sampler   *Sampler
+	samplerMu sync.RWMutex
+)
+
+// SetSampler sets the Sampler used by Error.Log and Errors.Log. Pass nil to disable sampling.
+func SetSampler(s *Sampler) {
+	samplerMu.Lock()
+	defer samplerMu.Unlock()
+
+	sampler = s
+}
+
+// GetSampler returns the active Sampler or nil if sampling is disabled.
+func GetSampler() *Sampler {
+	samplerMu.RLock()
+	defer samplerMu.RUnlock()
+
+	return sampler
+}
+
+// SamplePolicy decides how many similar errors are logged per interval. The first First errors
+// are logged, then every Thereafter'th error. A zero SamplePolicy logs everything.
+type SamplePolicy struct {
+	First      int
+	Thereafter int
+}
+
+// SamplerConfig holds the options for a Sampler.
+type SamplerConfig struct {
+	// Interval is the length of a sampling window.
+	Interval time.Duration
+	// Default is the policy used for Levels without an entry in Levels.
+	Default SamplePolicy
+	// Levels overrides Default per Level. FATAL is never sampled.
+	Levels map[Level]SamplePolicy
+	// MaxWindows caps how many groups of similar errors are tracked at once. Once reached, errors
+	// starting a new group share one extra window per Level, so messages that vary such as
+	// "user 123 not found" cannot grow the Sampler without bound.
+	MaxWindows int
+}
+
+// DefaultSamplerConfig logs the first 100 similar errors per second, then every 100th, tracking
+// up to 10000 groups of similar errors.
+func DefaultSamplerConfig() SamplerConfig {
+	return SamplerConfig{
+		Interval:   time.Second,
+		Default:    SamplePolicy{First: 100, Thereafter: 100},
+		Levels:     map[Level]SamplePolicy{},
+		MaxWindows: 10000,
+	}
+}
+
+// Sampler limits how often similar errors are logged. Errors are similar when they have the same
+// Level and Code, or the same Level and Message if they have no Code. When a window closes with
+// suppressed errors, a summary Error is logged in their place.
+//
+// Allow forgets closed windows once per Interval, but only while errors keep arriving. Call Start
+// so the summary of the last window is logged, and its memory released, when the errors stop.
+type Sampler struct {
+	config SamplerConfig
+
+	mu        sync.Mutex
+	windows   map[sampleKey]*sampleWindow
+	lastSweep time.Time
+}
+
+type sampleKey struct {
+	level Level
+	code  string
+	msg   string
+	// overflow marks the shared window used once MaxWindows is reached.
+	overflow bool
+}
+
+type sampleWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// NewSampler creates a new Sampler. An Interval or MaxWindows of 0 uses the DefaultSamplerConfig
+// value.
+func NewSampler(c SamplerConfig) *Sampler {
+	if c.Interval <= 0 {
+		c.Interval = DefaultSamplerConfig().Interval
+	}
+
+	if c.MaxWindows <= 0 {
+		c.MaxWindows = DefaultSamplerConfig().MaxWindows
+	}
+
+	return &Sampler{config: c, windows: make(map[sampleKey]*sampleWindow)}
+}
+
+// Allow reports whether e should be logged. Once per Interval Allow logs the summaries of the
+// closed windows and forgets them.
+func (s *Sampler) Allow(e Error) bool {
+	if e.Level.IsFatal() {
+		return true
+	}
+
+	p := s.policy(e.Level)
+	if p.First <= 0 && p.Thereafter <= 0 {
+		return true
+	}
+
+	key := sampleKey{level: e.Level, code: e.Code()}
+	if key.code == "" {
+		key.msg = e.Message
+	}
+
+	t := clockTime()
+
+	s.mu.Lock()
+	var summaries []*Error
+	if t.Sub(s.lastSweep) >= s.config.Interval {
+		summaries = s.sweep(t, false)
+		s.lastSweep = t
+	}
+
+	w, ok := s.windows[key]
+	if !ok && len(s.windows) >= s.config.MaxWindows {
+		key = sampleKey{level: e.Level, overflow: true}
+		w, ok = s.windows[key]
+	}
+
+	if ok && t.Sub(w.start) >= s.config.Interval {
+		if summary := s.summary(key, w); summary != nil {
+			summaries = append(summaries, summary)
+		}
+		ok = false
+	}
+
+	if !ok {
+		w = &sampleWindow{start: t}
+		s.windows[key] = w
+	}
+
+	w.count++
+	allow := w.count <= p.First || (p.Thereafter > 0 && (w.count-p.First)%p.Thereafter == 0)
+	if !allow {
+		w.suppressed++
+	}
+	s.mu.Unlock()
+
+	for _, summary := range summaries {
+		log.Println(summary)
+	}
+
+	return allow
+}
+
+// Flush logs summaries for every window that has closed and forgets them. Pass force to log
+// summaries for windows that are still open, such as before exiting.
+func (s *Sampler) Flush(force bool) {
+	t := clockTime()
+
+	s.mu.Lock()
+	summaries := s.sweep(t, force)
+	s.mu.Unlock()
+
+	for _, summary := range summaries {
+		log.Println(summary)
+	}
+}
+
+// Start flushes closed windows every Interval so summaries are logged even when the errors
+// stop. Call the returned function to stop.
+func (s *Sampler) Start() (stop func()) {
+	ticker := time.NewTicker(s.config.Interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				s.Flush(false)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
+// sweep forgets the windows closed at t, or every window if force is set, and returns their
+// summaries. s.mu must be held.
+func (s *Sampler) sweep(t time.Time, force bool) []*Error {
+	var summaries []*Error
+	for key, w := range s.windows {
+		if !force && t.Sub(w.start) < s.config.Interval {
+			continue
+		}
+
+		if summary := s.summary(key, w); summary != nil {
+			summaries = append(summaries, summary)
+		}
+		delete(s.windows, key)
+	}
+
+	return summaries
+}
+
+func (s *Sampler) policy(level Level) SamplePolicy {
+	if p, ok := s.config.Levels[level]; ok {
+		return p
+	}
+
+	return s.config.Default
+}
+
+// summary returns the summary Error for w or nil if nothing was suppressed.
+func (s *Sampler) summary(key sampleKey, w *sampleWindow) *Error {
+	if w.suppressed == 0 {
+		return nil
+	}
+
+	t := clockTime()
+	e := Error{Level: key.level, Message: fmt.Sprintf("suppressed %d similar errors", w.suppressed)}
+	if config.Load().LogTime {
+		e.Time = &t
+	}
+
+	switch {
+	case key.overflow:
+		e.setMetadata("overflow", true)
+	case key.code != "":
+		e.setMetadata(CodeKey, key.code)
+	default:
+		e.setMetadata("message", key.msg)
+	}
+	e.setMetadata("suppressed", w.suppressed)
+	e.setMetadata("interval", s.config.Interval.String())
+
+	return &e
+}
+
+// sampled reports whether e is allowed by the active Sampler.
+func sampled(e Error) bool {
+	s := GetSampler()
+	return s == nil || s.Allow(e)
+}
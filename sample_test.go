@@ -0,0 +1,153 @@
+package jerrors
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplerAllow(t *testing.T) {
+	clock := setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	s := NewSampler(SamplerConfig{
+		Interval: time.Second,
+		Default:  SamplePolicy{First: 2, Thereafter: 3},
+	})
+
+	var allowed []bool
+	for i := 0; i < 8; i++ {
+		allowed = append(allowed, s.Allow(errorErr))
+	}
+	require.Equal(t, []bool{true, true, false, false, true, false, false, true}, allowed)
+	require.Empty(t, buf.String())
+
+	// Different messages are sampled separately.
+	require.True(t, s.Allow(NewError(ERROR, "other message")))
+
+	// Closing the window logs a summary of the suppressed errors.
+	clock.Add(time.Second)
+	require.True(t, s.Allow(errorErr))
+	require.Contains(t, buf.String(), `"level":"error","message":"suppressed 4 similar errors"`)
+	require.Contains(t, buf.String(), `"message":"test error"`)
+}
+
+func TestSamplerCode(t *testing.T) {
+	setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	s := NewSampler(SamplerConfig{Default: SamplePolicy{First: 1}})
+
+	// Errors with the same code are similar even when the messages differ.
+	require.True(t, s.Allow(NewError(WARN, "timeout 1", CodeKey, "E1")))
+	require.False(t, s.Allow(NewError(WARN, "timeout 2", CodeKey, "E1")))
+	require.True(t, s.Allow(NewError(WARN, "timeout 2", CodeKey, "E2")))
+}
+
+func TestSamplerLevels(t *testing.T) {
+	setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	s := NewSampler(SamplerConfig{
+		Default: SamplePolicy{First: 1},
+		Levels: map[Level]SamplePolicy{
+			WARN:  {},
+			FATAL: {First: 1},
+		},
+	})
+
+	require.True(t, s.Allow(errorErr))
+	require.False(t, s.Allow(errorErr))
+
+	// A zero policy never samples.
+	for i := 0; i < 5; i++ {
+		require.True(t, s.Allow(warnErr))
+	}
+
+	// FATAL is never sampled.
+	for i := 0; i < 5; i++ {
+		require.True(t, s.Allow(fatalErr))
+	}
+}
+
+func TestSamplerFlush(t *testing.T) {
+	clock := setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	s := NewSampler(SamplerConfig{Interval: time.Minute, Default: SamplePolicy{First: 1}})
+	s.Allow(errorErr)
+	s.Allow(errorErr)
+
+	// The window is still open.
+	s.Flush(false)
+	require.Empty(t, buf.String())
+
+	clock.Add(time.Minute)
+	s.Flush(false)
+	require.Contains(t, buf.String(), "suppressed 1 similar errors")
+
+	// Nothing left to flush.
+	buf.Reset()
+	s.Allow(errorErr)
+	s.Flush(true)
+	require.Empty(t, buf.String())
+
+	s.Allow(errorErr)
+	s.Allow(errorErr)
+	s.Flush(true)
+	require.Contains(t, buf.String(), "suppressed 1 similar errors")
+}
+
+func TestSamplerWindows(t *testing.T) {
+	clock := setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	s := NewSampler(SamplerConfig{Interval: time.Second, Default: SamplePolicy{First: 1}, MaxWindows: 3})
+	for i := 0; i < 10; i++ {
+		s.Allow(NewError(ERROR, fmt.Sprintf("user %d not found", i)))
+	}
+	// Messages past MaxWindows share one window per Level.
+	require.Len(t, s.windows, 4)
+	require.False(t, s.Allow(NewError(ERROR, "user 10 not found")))
+
+	// Closed windows are forgotten by Allow without Flush or Start.
+	clock.Add(time.Second)
+	require.True(t, s.Allow(errorErr))
+	require.Len(t, s.windows, 1)
+	require.Contains(t, buf.String(), `"metadata":{"overflow":"true","suppressed":"7"`)
+}
+
+func TestSamplerLog(t *testing.T) {
+	SetConfig(DefaultConfig())
+	setTestClock(t, time.Date(2024, 7, 18, 13, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	SetSampler(NewSampler(SamplerConfig{Default: SamplePolicy{First: 2}}))
+	defer SetSampler(nil)
+
+	err := errorErr
+	for i := 0; i < 5; i++ {
+		err.Log()
+	}
+	require.Equal(t, 2, strings.Count(buf.String(), "\n"))
+
+	buf.Reset()
+	errs := New()
+	errs.Add(infoErr)
+	errs.Add(infoErr)
+	errs.Add(infoErr)
+	errs.Add(warnErr)
+	errs.Log()
+	require.Equal(t, 2, strings.Count(buf.String(), `"level":"info"`))
+	require.Equal(t, 1, strings.Count(buf.String(), `"level":"warn"`))
+}
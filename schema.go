@@ -0,0 +1,172 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:generate go run ./cmd/jerrors schema -o jerrors.schema.json
+
+// SchemaVersion is the version of the JSON wire format described by Schema. It is written as
+// "schema_version" on each Error when Config.LogSchemaVersion is set and changes whenever a
+// change to the format could break a consumer.
+const SchemaVersion = "1"
+
+// SchemaID is the $id of the schema returned by Schema.
+const SchemaID = "https://raw.githubusercontent.com/chadeldridge/jerrors/main/jerrors.schema.json"
+
+// jsonSchema is the subset of JSON Schema draft 2020-12 used to describe the wire format.
+// Type is a string or a []string. AdditionalProperties is false or a *jsonSchema.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	OneOf                []*jsonSchema          `json:"oneOf,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+}
+
+// schemaTypes describes the types that have their own MarshalJSON.
+var schemaTypes = map[reflect.Type]func() *jsonSchema{
+	reflect.TypeOf(Level(0)): func() *jsonSchema {
+		s := &jsonSchema{Type: "string", Description: "The Level. An empty level means none was set."}
+		for l := Level(0); l <= FATAL; l++ {
+			s.Enum = append(s.Enum, l.String())
+		}
+		return s
+	},
+	reflect.TypeOf(FieldPath{}): func() *jsonSchema {
+		return &jsonSchema{Type: "string", Format: "json-pointer", Description: "The field the Error applies to as an RFC 6901 JSON Pointer."}
+	},
+	reflect.TypeOf(time.Time{}): func() *jsonSchema {
+		return &jsonSchema{Type: []string{"string", "integer"}, Description: "When the Error was created, written with Config.TimeFormat. Unix formats are integers."}
+	},
+}
+
+// schemaExtras are the properties written by a type's MarshalJSON that are not struct fields.
+var schemaExtras = map[reflect.Type]map[string]*jsonSchema{
+	reflect.TypeOf(Error{}): {
+		"retry_after": {
+			Type:        "string",
+			Pattern:     `^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`,
+			Description: "How long to wait before trying again as a Go duration, such as 1.5s.",
+		},
+		"fingerprint": {
+			Type:        "string",
+			Pattern:     "^[0-9a-f]{16}$",
+			Description: "The Fingerprint, written when Config.LogFingerprint is set.",
+		},
+		"schema_version": {
+			Type:        "string",
+			Enum:        []string{SchemaVersion},
+			Description: "The SchemaVersion, written when Config.LogSchemaVersion is set.",
+		},
+	},
+}
+
+// schemaDescriptions are the descriptions of the types in $defs.
+var schemaDescriptions = map[string]string{
+	"Error":     "A single Error as written by Error.String and Error.Log.",
+	"Errors":    "An Errors list with its child groups as written by json.Marshal and Errors.Pretty.",
+	"ErrorList": "The Errors of an Errors list, with groups flattened, as written by Errors.Error and Errors.Log.",
+	"Position":  "The place in a source file an Error applies to. Lines and columns start at 1.",
+}
+
+var schema = sync.OnceValue(func() *jsonSchema {
+	defs := map[string]*jsonSchema{}
+	schemaFor(reflect.TypeOf(Error{}), defs)
+	schemaFor(reflect.TypeOf(Errors{}), defs)
+	defs["ErrorList"] = &jsonSchema{Type: "array", Items: &jsonSchema{Ref: "#/$defs/Error"}}
+	for name, d := range defs {
+		d.Description = schemaDescriptions[name]
+	}
+
+	return &jsonSchema{
+		Schema:      "https://json-schema.org/draft/2020-12/schema",
+		ID:          SchemaID,
+		Title:       "jerrors wire format version " + SchemaVersion,
+		Description: "An Error, a list of Errors or an Errors object with groups.",
+		OneOf: []*jsonSchema{
+			{Ref: "#/$defs/Error"},
+			{Ref: "#/$defs/ErrorList"},
+			{Ref: "#/$defs/Errors"},
+		},
+		Defs: defs,
+	}
+})
+
+// Schema returns the JSON Schema of the JSON written for an Error, an Errors list by
+// Errors.Error and an Errors object with groups. It is generated from the Go types and published
+// as jerrors.schema.json. See Validate.
+func Schema() []byte {
+	b, _ := json.MarshalIndent(schema(), "", "  ")
+	return append(b, '\n')
+}
+
+// schemaFor returns the schema of t. Structs are added to defs by name and referenced.
+func schemaFor(t reflect.Type, defs map[string]*jsonSchema) *jsonSchema {
+	if f, ok := schemaTypes[t]; ok {
+		return f()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), defs)
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		min := 0
+		return &jsonSchema{Type: "integer", Minimum: &min}
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: schemaFor(t.Elem(), defs)}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: schemaFor(t.Elem(), defs)}
+	case reflect.Struct:
+		ref := &jsonSchema{Ref: "#/$defs/" + t.Name()}
+		if _, ok := defs[t.Name()]; ok {
+			return ref
+		}
+
+		s := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}, AdditionalProperties: false}
+		defs[t.Name()] = s
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+
+			if name == "" {
+				name = f.Name
+			}
+
+			s.Properties[name] = schemaFor(f.Type, defs)
+			if !strings.Contains(opts, "omitempty") {
+				s.Required = append(s.Required, name)
+			}
+		}
+
+		for name, p := range schemaExtras[t] {
+			s.Properties[name] = p
+		}
+
+		return ref
+	}
+
+	return &jsonSchema{}
+}
@@ -0,0 +1,141 @@
+package jerrors
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFile(t *testing.T) {
+	b, err := os.ReadFile("jerrors.schema.json")
+	require.Nil(t, err)
+	require.Equal(t, string(Schema()), string(b), "jerrors.schema.json is out of date, run go generate")
+}
+
+func TestSchemaDefs(t *testing.T) {
+	var s struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"$defs"`
+	}
+	require.Nil(t, json.Unmarshal(Schema(), &s))
+
+	require.ElementsMatch(t, []string{"Error", "Errors", "ErrorList", "Position"}, keys(s.Defs))
+	require.ElementsMatch(t, []string{
+		"time", "level", "message", "field", "position", "retryable", "temporary", "metadata",
+		"retry_after", "fingerprint", "schema_version",
+	}, keys(s.Defs["Error"].Properties))
+	require.Equal(t, []string{"errors", "level"}, s.Defs["Errors"].Required)
+}
+
+func keys[V any](m map[string]V) []string {
+	k := make([]string, 0, len(m))
+	for key := range m {
+		k = append(k, key)
+	}
+
+	return k
+}
+
+func TestValidateOutput(t *testing.T) {
+	for _, format := range []string{TimeRFC3339Nano, TimeUnixMilli} {
+		t.Run(format, func(t *testing.T) {
+			c := DefaultConfig()
+			c.TimeFormat = format
+			c.LogCaller = true
+			c.LogFingerprint = true
+			c.LogSchemaVersion = true
+			SetConfig(c)
+			defer SetConfig(DefaultConfig())
+
+			e := NewError(ERROR, testMessage, CodeKey, "bad_port").At(Position{File: "app.conf", Line: 3, Column: 7})
+			e.Field = ParseFieldPath("server.ports[0]")
+			e.Retryable = true
+			e.RetryAfter = 1500 * time.Millisecond
+			require.Nil(t, Validate([]byte(e.String())))
+
+			errs := New()
+			errs.Add(e)
+			errs.Group("db").NewError(WARN, "slow query")
+			require.Nil(t, Validate([]byte(errs.Error())))
+			require.Nil(t, Validate([]byte(errs.Pretty())))
+
+			empty := New()
+			require.Nil(t, Validate([]byte(empty.Error())))
+			require.Nil(t, Validate([]byte(empty.Pretty())))
+
+			// The zero Errors, alone or as a group, has a nil Errors list.
+			var zero Errors
+			b, err := json.Marshal(zero)
+			require.Nil(t, err)
+			require.Nil(t, Validate(b))
+			zero.Groups = append(zero.Groups, &Errors{Name: "db"})
+			require.Nil(t, Validate([]byte(zero.Pretty())))
+		})
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want map[string]string
+	}{
+		{"level", `{"level":"loud","message":"m"}`, map[string]string{"/level": `must be one of "", "debug", "info", "warn", "error", "fatal"`}},
+		{"unknown key", `{"message":"m","extra":1}`, map[string]string{"/extra": "is not allowed"}},
+		{"metadata value", `{"message":"m","metadata":{"port":8080}}`, map[string]string{"/metadata/port": "must be of type string"}},
+		{"field", `{"message":"m","field":"server.port"}`, map[string]string{"/field": "must be a JSON Pointer"}},
+		{"time", `{"time":true}`, map[string]string{"/time": "must be of type string or integer"}},
+		{"retry after", `{"retry_after":"soon"}`, map[string]string{"/retry_after": `must match ^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`}},
+		{"schema version", `{"schema_version":"0"}`, map[string]string{"/schema_version": `must be one of "1"`}},
+		{"position", `{"position":{"line":-1,"file":"a"}}`, map[string]string{"/position/line": "must be at least 0"}},
+		{"list", `[{"message":"m"},{"level":2}]`, map[string]string{"/1/level": "must be of type string"}},
+		{"errors required", `{"errors":[]}`, map[string]string{"/level": "is required"}},
+		{"group", `{"errors":[],"level":"","groups":[{"name":"db","errors":[{"message":1}],"level":""}]}`, map[string]string{"/groups/0/errors/0/message": "must be of type string"}},
+		{"type", `"error"`, map[string]string{"": "must be of type object"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.json))
+			require.NotNil(t, err)
+
+			errs, ok := err.(*Errors)
+			require.True(t, ok, err)
+
+			got := map[string]string{}
+			for _, e := range errs.Errors {
+				got[e.Field.Pointer()] = e.Message
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateNotJSON(t *testing.T) {
+	err := Validate([]byte(`{"message":`))
+	require.NotNil(t, err)
+	_, ok := err.(*Errors)
+	require.False(t, ok)
+
+	require.NotNil(t, Validate([]byte(`{} {}`)))
+}
+
+func TestLogSchemaVersion(t *testing.T) {
+	c := DefaultConfig()
+	c.LogTime = false
+	c.LogSchemaVersion = true
+	SetConfig(c)
+	defer SetConfig(DefaultConfig())
+
+	e := NewError(ERROR, testMessage)
+	require.Equal(t, `{"schema_version":"1","level":"error","message":"`+testMessage+`"}`, e.String())
+
+	var got Error
+	require.Nil(t, json.Unmarshal([]byte(e.String()), &got))
+	require.True(t, e.Equal(got))
+}
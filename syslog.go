@@ -0,0 +1,318 @@
+package jerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat selects the syslog message format.
+type SyslogFormat int
+
+const (
+	// RFC5424 is the current syslog protocol with structured data.
+	RFC5424 SyslogFormat = iota
+	// RFC3164 is the legacy BSD syslog format.
+	RFC3164
+)
+
+// Syslog severities as defined in RFC 5424 section 6.2.1.
+const (
+	SeverityEmergency = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+// FacilityUser is the default syslog facility (user-level messages).
+const FacilityUser = 1
+
+// nilValue is the RFC 5424 NILVALUE used for empty header fields.
+const nilValue = "-"
+
+// rfc5424Time is the RFC 5424 TIMESTAMP layout. TIME-SECFRAC may have at most 6 digits.
+const rfc5424Time = "2006-01-02T15:04:05.999999Z07:00"
+
+// SyslogSeverity maps a Level to its syslog severity. A Level of 0 maps to notice.
+func (l Level) SyslogSeverity() int {
+	switch l {
+	case DEBUG:
+		return SeverityDebug
+	case INFO:
+		return SeverityInfo
+	case WARN:
+		return SeverityWarning
+	case ERROR:
+		return SeverityError
+	case FATAL:
+		return SeverityCritical
+	default:
+		return SeverityNotice
+	}
+}
+
+// SyslogConfig holds the connection and formatting options for a Syslog writer.
+type SyslogConfig struct {
+	// Network is one of "udp", "tcp", "unix" or "unixgram". Stream networks (tcp, unix) use
+	// octet-counting framing (RFC 6587).
+	Network string
+	// Address is the host:port or socket path of the syslog server.
+	Address string
+	// Format is RFC5424 or RFC3164.
+	Format SyslogFormat
+	// Facility is the syslog facility code. Defaults to FacilityUser.
+	Facility int
+	// Hostname is sent in the header. Defaults to os.Hostname().
+	Hostname string
+	// AppName is sent as APP-NAME (RFC 5424) or TAG (RFC 3164). Defaults to the binary name.
+	AppName string
+	// SDID is the structured data element ID Metadata is placed in, in the RFC 5424 name@number
+	// form for IDs that are not registered with IANA. number is a private enterprise number.
+	// Defaults to "meta@32473", using the number reserved for documentation.
+	SDID string
+	// DialTimeout limits how long connecting may take.
+	DialTimeout time.Duration
+}
+
+// DefaultSyslogConfig returns a config for RFC 5424 over UDP to localhost:514.
+func DefaultSyslogConfig() SyslogConfig {
+	host, _ := os.Hostname()
+
+	return SyslogConfig{
+		Network:     "udp",
+		Address:     "localhost:514",
+		Format:      RFC5424,
+		Facility:    FacilityUser,
+		Hostname:    host,
+		AppName:     filepath.Base(os.Args[0]),
+		SDID:        "meta@32473",
+		DialTimeout: 5 * time.Second,
+	}
+}
+
+// Syslog sends Errors to a syslog server. It implements io.Writer by decoding each JSON line
+// written to it, so it can be used with SetLogOutput. Failed writes reconnect and retry once.
+type Syslog struct {
+	config SyslogConfig
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslog connects to the syslog server described by c.
+func NewSyslog(c SyslogConfig) (*Syslog, error) {
+	d := DefaultSyslogConfig()
+	if c.Network == "" {
+		c.Network = d.Network
+	}
+	if c.Facility == 0 {
+		c.Facility = d.Facility
+	}
+	if c.Hostname == "" {
+		c.Hostname = d.Hostname
+	}
+	if c.AppName == "" {
+		c.AppName = d.AppName
+	}
+	if c.SDID == "" {
+		c.SDID = d.SDID
+	}
+	if !validSDID(c.SDID) {
+		return nil, fmt.Errorf("jerrors: invalid syslog SDID %q, want name@number", c.SDID)
+	}
+
+	s := &Syslog{config: c}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Write decodes each line of p as an Error and sends it. Lines that are not Errors are sent as
+// notice messages.
+func (s *Syslog) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var e Error
+		if err := json.Unmarshal(line, &e); err != nil || e.Message == "" {
+			e = Error{Message: string(line)}
+		}
+
+		if err := s.WriteError(e); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// WriteError formats e and sends it to the syslog server.
+func (s *Syslog) WriteError(e Error) error {
+	msg := s.Format(e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if err := s.send(msg); err == nil {
+			return nil
+		}
+	}
+
+	// Reconnect and try once more.
+	if err := s.connect(); err != nil {
+		return err
+	}
+
+	return s.send(msg)
+}
+
+// Close closes the connection to the syslog server.
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// Format returns e as a syslog message in the configured format, without transport framing.
+func (s *Syslog) Format(e Error) string {
+	e = e.Resolve()
+	t := clockTime()
+	if e.Time != nil {
+		t = *e.Time
+	}
+
+	pri := s.config.Facility*8 + e.Level.SyslogSeverity()
+
+	if s.config.Format == RFC3164 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "<%d>%s %s %s[%d]: %s", pri, t.Format(time.Stamp), s.config.Hostname,
+			s.config.AppName, os.Getpid(), e.Message)
+		for _, k := range e.MetadataKeys() {
+			fmt.Fprintf(&b, " %s=%q", k, e.Metadata[k])
+		}
+		return b.String()
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s", pri, t.Format(rfc5424Time),
+		headerField(s.config.Hostname, 255), headerField(s.config.AppName, 48), os.Getpid(),
+		headerField(e.Level.String(), 32), s.structuredData(e), e.Message)
+}
+
+func (s *Syslog) connect() error {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	conn, err := net.DialTimeout(s.config.Network, s.config.Address, s.config.DialTimeout)
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	return nil
+}
+
+func (s *Syslog) send(msg string) error {
+	switch s.config.Network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	_, err := s.conn.Write([]byte(msg))
+	return err
+}
+
+// structuredData renders the Metadata of e as a single SD-ELEMENT. Returns NILVALUE if it is empty.
+func (s *Syslog) structuredData(e Error) string {
+	if len(e.Metadata) == 0 {
+		return nilValue
+	}
+
+	var b strings.Builder
+	b.WriteString("[" + sdName(s.config.SDID))
+	for _, k := range e.MetadataKeys() {
+		b.WriteString(" " + sdName(k) + `="` + sdEscaper.Replace(e.Metadata[k]) + `"`)
+	}
+	b.WriteString("]")
+
+	return b.String()
+}
+
+// sdEscaper escapes the characters RFC 5424 requires escaping in PARAM-VALUE.
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// sdName makes s a valid SD-NAME: printable US-ASCII without '=', ' ', ']' or '"', max 32 chars.
+func sdName(s string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < 32; i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+
+	if len(b) == 0 {
+		return "_"
+	}
+
+	return string(b)
+}
+
+// validSDID returns true if id is an RFC 5424 SD-ID of the form name@number, where name is an
+// SD-NAME without '@' and number is a private enterprise number, optionally followed by
+// dot-separated numbers.
+func validSDID(id string) bool {
+	name, number, ok := strings.Cut(id, "@")
+	if !ok || name == "" || len(id) > 32 || sdName(name) != name || number == "" {
+		return false
+	}
+
+	for _, part := range strings.Split(number, ".") {
+		if part == "" || strings.Trim(part, "0123456789") != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// headerField returns s as a valid RFC 5424 header field, or NILVALUE if nothing is left of it.
+func headerField(s string, max int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < max; i++ {
+		if c := s[i]; c > ' ' && c <= '~' {
+			b = append(b, c)
+		}
+	}
+
+	if len(b) == 0 {
+		return nilValue
+	}
+
+	return string(b)
+}
@@ -0,0 +1,202 @@
+package jerrors
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSyslogConfig(network, address string) SyslogConfig {
+	c := DefaultSyslogConfig()
+	c.Network = network
+	c.Address = address
+	c.Hostname = "host1"
+	c.AppName = "app"
+	return c
+}
+
+// readFrame reads a single octet-counted syslog frame.
+func readFrame(r *bufio.Reader) (string, error) {
+	size, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(size))
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return string(b), err
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	require.Equal(t, SeverityDebug, DEBUG.SyslogSeverity())
+	require.Equal(t, SeverityInfo, INFO.SyslogSeverity())
+	require.Equal(t, SeverityWarning, WARN.SyslogSeverity())
+	require.Equal(t, SeverityError, ERROR.SyslogSeverity())
+	require.Equal(t, SeverityCritical, FATAL.SyslogSeverity())
+	require.Equal(t, SeverityNotice, Level(0).SyslogSeverity())
+}
+
+func TestSyslogFormat(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer pc.Close()
+
+	s, err := NewSyslog(testSyslogConfig("udp", pc.LocalAddr().String()))
+	require.Nil(t, err)
+	defer s.Close()
+
+	tm := time.Date(2024, 7, 18, 13, 9, 25, 0, time.UTC)
+	e := Error{
+		Time:     &tm,
+		Level:    ERROR,
+		Message:  testMessage,
+		Metadata: map[string]string{"user": `a"b]c\`, "bad key": "v"},
+	}
+
+	// <1*8+3> = <11>
+	require.Regexp(t,
+		`^<11>1 2024-07-18T13:09:25Z host1 app \d+ error \[meta@32473 bad_key="v" user="a\\"b\\]c\\\\"\] test error$`,
+		s.Format(e))
+
+	s.config.Format = RFC3164
+	require.Regexp(t, `^<11>Jul 18 13:09:25 host1 app\[\d+\]: test error bad key="v" user=`, s.Format(e))
+
+	// Fractions of a second are cut to microseconds.
+	s.config.Format = RFC5424
+	ns := tm.Add(123456789)
+	e.Time = &ns
+	require.Regexp(t, `^<11>1 2024-07-18T13:09:25\.123456Z host1 app `, s.Format(e))
+	e.Time = &tm
+
+	// No metadata is the NILVALUE.
+	e.Metadata = nil
+	require.Contains(t, s.Format(e), " error - test error")
+
+	// Header fields with nothing printable are the NILVALUE.
+	s.config.Hostname = " "
+	s.config.AppName = "\t"
+	require.Regexp(t, `^<11>1 2024-07-18T13:09:25Z - - \d+ error - test error$`, s.Format(e))
+}
+
+func TestSyslogSDID(t *testing.T) {
+	for _, id := range []string{"meta@32473", "jerrors@1.2.3", "a@0"} {
+		require.True(t, validSDID(id), id)
+	}
+
+	for _, id := range []string{"meta", "@32473", "meta@", "meta@abc", "meta@1..2", "meta@1.", "me ta@1", "a@b@1",
+		"meta@32473" + strings.Repeat("0", 32)} {
+		require.False(t, validSDID(id), id)
+	}
+
+	c := testSyslogConfig("udp", "127.0.0.1:514")
+	c.SDID = "meta"
+	_, err := NewSyslog(c)
+	require.Error(t, err)
+}
+
+func TestSyslogUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer pc.Close()
+
+	s, err := NewSyslog(testSyslogConfig("udp", pc.LocalAddr().String()))
+	require.Nil(t, err)
+	defer s.Close()
+
+	require.Nil(t, s.WriteError(NewError(WARN, testMessage, mdUserKey, mdUserVal)))
+
+	b := make([]byte, 1024)
+	require.Nil(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := pc.ReadFrom(b)
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(string(b[:n]), "<12>1 "))
+	require.Contains(t, string(b[:n]), `[meta@32473 user="test1"] test error`)
+}
+
+func TestSyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+
+	msgs := make(chan string, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					msg, err := readFrame(r)
+					if err != nil {
+						return
+					}
+					msgs <- msg
+				}
+			}(conn)
+		}
+	}()
+
+	s, err := NewSyslog(testSyslogConfig("tcp", ln.Addr().String()))
+	require.Nil(t, err)
+	defer s.Close()
+
+	// Write decodes the JSON lines written by log.
+	SetConfig(DefaultConfig())
+	n, err := s.Write([]byte(errorErr.String() + "\n" + infoErr.String() + "\n"))
+	require.Nil(t, err)
+	require.NotZero(t, n)
+	require.True(t, strings.HasPrefix(<-msgs, "<11>1 "))
+	require.True(t, strings.HasPrefix(<-msgs, "<14>1 "))
+
+	// Reconnects after the connection is lost.
+	s.Close()
+	require.Nil(t, s.WriteError(NewError(DEBUG, testMessage)))
+	require.True(t, strings.HasPrefix(<-msgs, "<15>1 "))
+}
+
+func TestSyslogUnix(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "log.sock")
+	ln, err := net.Listen("unix", addr)
+	require.Nil(t, err)
+	defer ln.Close()
+
+	msgs := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		msg, _ := readFrame(bufio.NewReader(conn))
+		msgs <- msg
+	}()
+
+	s, err := NewSyslog(testSyslogConfig("unix", addr))
+	require.Nil(t, err)
+	defer s.Close()
+
+	_, err = s.Write([]byte("not json\n"))
+	require.Nil(t, err)
+	require.Regexp(t, `^<13>1 .* - not json$`, <-msgs)
+}
+
+func TestSyslogDialError(t *testing.T) {
+	_, err := NewSyslog(testSyslogConfig("unix", filepath.Join(t.TempDir(), "missing.sock")))
+	require.Error(t, err)
+}
@@ -0,0 +1,218 @@
+package jerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Validate checks that b is an Error, a list of Errors or an Errors object matching Schema.
+// Returns nil, the error if b is not JSON, or an *Errors with a Field Error for each problem.
+// Field paths are JSON Pointers into b.
+func Validate(b []byte) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+
+	if _, err := d.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("jerrors: unexpected data after JSON value")
+	}
+
+	errs := New()
+	root := schema()
+	root.validate(root, v, nil, &errs)
+	if errs.IsEmpty() {
+		return nil
+	}
+
+	return &errs
+}
+
+// validate adds an Error to errs for each way v, found at path, does not match s.
+func (s *jsonSchema) validate(root *jsonSchema, v any, path FieldPath, errs *Errors) {
+	if s.Ref != "" {
+		root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")].validate(root, v, path, errs)
+		return
+	}
+
+	if len(s.OneOf) > 0 {
+		s.validateOneOf(root, v, path, errs)
+		return
+	}
+
+	if s.Type != nil {
+		var types []string
+		switch t := s.Type.(type) {
+		case string:
+			types = []string{t}
+		case []string:
+			types = t
+		}
+
+		if got := jsonType(v); !slices.Contains(types, got) && !(got == "integer" && slices.Contains(types, "number")) {
+			errs.Add(fieldError(path, "must be of type "+strings.Join(types, " or "), "type", got))
+			return
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		s.validateString(v, path, errs)
+	case json.Number:
+		if n, err := v.Int64(); err == nil && s.Minimum != nil && n < int64(*s.Minimum) {
+			errs.Add(fieldError(path, fmt.Sprintf("must be at least %d", *s.Minimum), "value", v))
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(root, item, path.Join(FieldPath{{Index: i, IsIndex: true}}), errs)
+			}
+		}
+	case map[string]any:
+		s.validateObject(root, v, path, errs)
+	}
+}
+
+// validateOneOf checks that v matches exactly one of s.OneOf. If it matches none, the problems
+// with the closest match are added, see fit.
+func (s *jsonSchema) validateOneOf(root *jsonSchema, v any, path FieldPath, errs *Errors) {
+	var (
+		best    Errors
+		bestFit = -1
+		matched int
+	)
+	for _, o := range s.OneOf {
+		oerrs := New()
+		o.validate(root, v, path, &oerrs)
+		if oerrs.IsEmpty() {
+			matched++
+			continue
+		}
+
+		if f := o.fit(root, v); bestFit < 0 || f < bestFit {
+			best, bestFit = oerrs, f
+		}
+	}
+
+	switch {
+	case matched == 0:
+		errs.Append(best)
+	case matched > 1:
+		errs.Add(fieldError(path, "must match exactly one schema", "matches", matched))
+	}
+}
+
+// fit returns how far v is from the shape of s: the number of keys s does not allow, or a large
+// number if v is not even of the right type. Lower is closer.
+func (s *jsonSchema) fit(root *jsonSchema, v any) int {
+	if s.Ref != "" {
+		return root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")].fit(root, v)
+	}
+
+	if t, ok := s.Type.(string); ok && t != jsonType(v) {
+		return math.MaxInt
+	}
+
+	n := 0
+	if obj, ok := v.(map[string]any); ok && s.AdditionalProperties == false {
+		for k := range obj {
+			if _, ok := s.Properties[k]; !ok {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+func (s *jsonSchema) validateString(v string, path FieldPath, errs *Errors) {
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+		errs.Add(fieldError(path, "must be one of "+strings.Join(quoteAll(s.Enum), ", "), "value", v))
+	}
+
+	if s.Format == "json-pointer" && v != "" && !strings.HasPrefix(v, "/") {
+		errs.Add(fieldError(path, "must be a JSON Pointer", "value", v))
+	}
+
+	if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(v) {
+		errs.Add(fieldError(path, "must match "+s.Pattern, "value", v))
+	}
+}
+
+func (s *jsonSchema) validateObject(root *jsonSchema, v map[string]any, path FieldPath, errs *Errors) {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			errs.Add(fieldError(path.Join(FieldPath{{Name: name}}), "is required"))
+		}
+	}
+
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := path.Join(FieldPath{{Name: k}})
+		if prop, ok := s.Properties[k]; ok {
+			prop.validate(root, v[k], p, errs)
+			continue
+		}
+
+		switch extra := s.AdditionalProperties.(type) {
+		case bool:
+			if !extra {
+				errs.Add(fieldError(p, "is not allowed"))
+			}
+		case *jsonSchema:
+			extra.validate(root, v[k], p, errs)
+		}
+	}
+}
+
+// jsonType returns the JSON Schema type of a value decoded with json.Decoder.UseNumber.
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func fieldError(path FieldPath, msg string, args ...interface{}) Error {
+	e := NewError(ERROR, msg, args...)
+	e.Field = path
+	return e
+}
+
+func quoteAll(s []string) []string {
+	q := make([]string, len(s))
+	for i, v := range s {
+		q[i] = fmt.Sprintf("%q", v)
+	}
+
+	return q
+}